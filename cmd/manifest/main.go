@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/likimiad/ozon_fintech/internal/logger"
+	"github.com/likimiad/ozon_fintech/internal/persisted"
+	"log/slog"
+)
+
+// manifest generates the persisted query allowlist from the operations used by clients.
+// Every .graphql file under the source directory is registered exactly as written,
+// so clients must send the same text or its SHA-256 hash.
+func main() {
+	src := flag.String("src", "operations", "directory with client .graphql operation files")
+	out := flag.String("out", "persisted-queries.json", "path of the generated manifest")
+	flag.Parse()
+
+	manifest := make(persisted.Manifest)
+	err := filepath.WalkDir(*src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".graphql") {
+			return nil
+		}
+		query, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hash := manifest.Add(string(query))
+		slog.Info("registered operation", "file", path, "hash", hash)
+		return nil
+	})
+	if err != nil {
+		logger.FatalError("error reading operations", err)
+	}
+
+	if err := manifest.Save(*out); err != nil {
+		logger.FatalError("error writing manifest", err)
+	}
+	slog.Info("manifest generated", "operations", len(manifest), "path", *out)
+}
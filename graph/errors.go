@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/likimiad/ozon_fintech/graph/model"
+	"github.com/likimiad/ozon_fintech/internal/database"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errConflictCode = "CONFLICT"
+
+// errorCodes maps service errors to the codes clients check for.
+var errorCodes = map[error]string{
+	database.ErrPostDisabled:  "COMMENTS_DISABLED",
+	database.ErrAuthorBanned:  "AUTHOR_BANNED",
+	database.ErrThreadLocked:  "THREAD_LOCKED",
+	database.ErrEventsExpired: "EVENTS_EXPIRED",
+}
+
+// ErrorPresenter adds machine-readable codes to errors clients are expected to handle.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	var conflict *database.ConflictError
+	if errors.As(err, &conflict) {
+		errcode.Set(gqlErr, errConflictCode)
+		gqlErr.Extensions["currentVersion"] = conflict.CurrentVersion
+	}
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			errcode.Set(gqlErr, code)
+		}
+	}
+	var ban *database.BanError
+	if errors.As(err, &ban) && ban.Ban.ExpiresAt != nil {
+		gqlErr.Extensions["bannedUntil"] = ban.Ban.ExpiresAt
+	}
+	return gqlErr
+}
+
+// userErrors converts validation failures into user errors on the input argument.
+// Any other error is returned unchanged to be reported as a top-level error.
+func userErrors(err error) ([]*model.UserError, error) {
+	fieldErrs := database.FieldErrors(err)
+	if len(fieldErrs) == 0 {
+		return nil, err
+	}
+	userErrs := make([]*model.UserError, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		userErrs[i] = &model.UserError{
+			Field:   []string{"input", fieldErr.Field},
+			Message: fieldErr.Err.Error(),
+		}
+	}
+	return userErrs, nil
+}
@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type loadersKey struct{}
+
+// ? How long a batch waits for more keys, resolvers of sibling fields run concurrently
+const batchWait = time.Millisecond
+
+// loaders memoizes data shared by many fields of one query, such as the comment
+// tree that every replies field of a post pages through, and batches the author
+// lookups of a list into one.
+type loaders struct {
+	mu       sync.Mutex
+	comments map[uint]*loaded[*database.CommentTree]
+	authors  *batcher[string, *models.User]
+}
+
+// loaded is a value loaded at most once, however many resolvers ask for it concurrently.
+type loaded[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+// get runs load on the first call and returns its result to every call.
+func (l *loaded[T]) get(load func() (T, error)) (T, error) {
+	l.once.Do(func() {
+		l.value, l.err = load()
+	})
+	return l.value, l.err
+}
+
+// batcher gathers the keys asked for within batchWait and fetches them with one call.
+// Each key is fetched at most once.
+type batcher[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	batches map[K]*batch[K, V] // ? Batch that fetches each key asked for so far
+	open    *batch[K, V]       // ? Batch still collecting keys
+}
+
+// batch is one fetch of several keys.
+type batch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// newBatcher creates a batcher fetching with fetch.
+func newBatcher[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batcher[K, V] {
+	return &batcher[K, V]{fetch: fetch, batches: make(map[K]*batch[K, V])}
+}
+
+// load returns the value of key once the batch fetching it completes.
+func (b *batcher[K, V]) load(key K) (V, error) {
+	b.mu.Lock()
+	current, ok := b.batches[key]
+	if !ok {
+		if b.open == nil {
+			b.open = &batch[K, V]{done: make(chan struct{})}
+			time.AfterFunc(batchWait, b.dispatch)
+		}
+		current = b.open
+		current.keys = append(current.keys, key)
+		b.batches[key] = current
+	}
+	b.mu.Unlock()
+
+	<-current.done
+	return current.values[key], current.err
+}
+
+// dispatch closes the open batch and fetches its keys.
+func (b *batcher[K, V]) dispatch() {
+	b.mu.Lock()
+	current := b.open
+	b.open = nil
+	b.mu.Unlock()
+
+	current.values, current.err = b.fetch(current.keys)
+	close(current.done)
+}
+
+// Loaders installs fresh loaders for each query. Mutations and subscriptions change
+// or outlive the data, so their fields always load it directly.
+func (r *Resolver) Loaders(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if oc := graphql.GetOperationContext(ctx); oc.Operation != nil && oc.Operation.Operation == ast.Query {
+		ctx = context.WithValue(ctx, loadersKey{}, &loaders{
+			comments: make(map[uint]*loaded[*database.CommentTree]),
+			authors:  newBatcher(r.PostService.GetAuthors),
+		})
+	}
+	return next(ctx)
+}
+
+// commentTree returns the comments of a post the viewer may see, loaded once per query.
+func (r *Resolver) commentTree(ctx context.Context, postID uint) (*database.CommentTree, error) {
+	viewer := auth.FromContext(ctx)
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return r.PostService.GetCommentTree(postID, viewer)
+	}
+
+	l.mu.Lock()
+	entry, ok := l.comments[postID]
+	if !ok {
+		entry = &loaded[*database.CommentTree]{}
+		l.comments[postID] = entry
+	}
+	l.mu.Unlock()
+
+	return entry.get(func() (*database.CommentTree, error) {
+		return r.PostService.GetCommentTree(postID, viewer)
+	})
+}
+
+// author returns the profile behind an author handle, batched with the other authors of a query.
+func (r *Resolver) author(ctx context.Context, handle string) (*models.User, error) {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		authors, err := r.PostService.GetAuthors([]string{handle})
+		if err != nil {
+			return nil, err
+		}
+		return authors[handle], nil
+	}
+	return l.authors.load(handle)
+}
@@ -2,11 +2,261 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+)
+
+type CreateCommentInput struct {
+	PostID    string  `json:"postId"`
+	CommentID *string `json:"commentId,omitempty"`
+	// Must be the X-User handle of the request.
+	Author  string `json:"author"`
+	Content string `json:"content"`
+	// Retries with the same key return the originally created comment.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+}
+
+type CreateCommentPayload struct {
+	Comment    *models.Comment `json:"comment,omitempty"`
+	UserErrors []*UserError    `json:"userErrors"`
+}
+
+type CreatePostInput struct {
+	Title           string `json:"title"`
+	Content         string `json:"content"`
+	Author          string `json:"author"`
+	CommentsEnabled bool   `json:"commentsEnabled"`
+	// Retries with the same key return the originally created post.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+}
+
+type CreatePostPayload struct {
+	Post       *models.Post `json:"post,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type CreateWebhookInput struct {
+	URL    string                `json:"url"`
+	Events []models.WebhookEvent `json:"events"`
+}
+
+type CreateWebhookPayload struct {
+	Webhook *models.Webhook `json:"webhook,omitempty"`
+	// Key of the X-Webhook-Signature HMAC. It is only returned here.
+	Secret     *string      `json:"secret,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
 type Mutation struct {
 }
 
+type PostFilter struct {
+	Author          *string    `json:"author,omitempty"`
+	CreatedAfter    *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore   *time.Time `json:"createdBefore,omitempty"`
+	CommentsEnabled *bool      `json:"commentsEnabled,omitempty"`
+	HasComments     *bool      `json:"hasComments,omitempty"`
+}
+
+type PostOrder struct {
+	Field     PostOrderField `json:"field"`
+	Direction OrderDirection `json:"direction"`
+}
+
 type Query struct {
 }
 
 type Subscription struct {
 }
+
+type UpdateCommentInput struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	// Fails with a CONFLICT error when the comment was updated since this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdateCommentPayload struct {
+	Comment    *models.Comment `json:"comment,omitempty"`
+	UserErrors []*UserError    `json:"userErrors"`
+}
+
+type UpdatePostInput struct {
+	ID              string  `json:"id"`
+	Title           *string `json:"title,omitempty"`
+	Content         *string `json:"content,omitempty"`
+	CommentsEnabled *bool   `json:"commentsEnabled,omitempty"`
+	// Fails with a CONFLICT error when the post was updated since this version.
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type UpdatePostPayload struct {
+	Post       *models.Post `json:"post,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Updates the profile of the viewer. Omitted fields are left unchanged.
+type UpdateProfileInput struct {
+	DisplayName *string `json:"displayName,omitempty"`
+	Bio         *string `json:"bio,omitempty"`
+	AvatarURL   *string `json:"avatarUrl,omitempty"`
+}
+
+type UpdateProfilePayload struct {
+	User       *models.User `json:"user,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// Updates a webhook. Omitted fields are left unchanged.
+type UpdateWebhookInput struct {
+	ID     string                `json:"id"`
+	URL    *string               `json:"url,omitempty"`
+	Events []models.WebhookEvent `json:"events,omitempty"`
+	Active *bool                 `json:"active,omitempty"`
+}
+
+type UpdateWebhookPayload struct {
+	Webhook    *models.Webhook `json:"webhook,omitempty"`
+	UserErrors []*UserError    `json:"userErrors"`
+}
+
+// A validation failure of a mutation input. The field is the path to the
+// offending argument, e.g. ["input", "title"].
+type UserError struct {
+	Field   []string `json:"field"`
+	Message string   `json:"message"`
+}
+
+type CommentOrder string
+
+const (
+	CommentOrderOldest CommentOrder = "OLDEST"
+	CommentOrderNewest CommentOrder = "NEWEST"
+	CommentOrderTop    CommentOrder = "TOP"
+	CommentOrderActive CommentOrder = "ACTIVE"
+)
+
+var AllCommentOrder = []CommentOrder{
+	CommentOrderOldest,
+	CommentOrderNewest,
+	CommentOrderTop,
+	CommentOrderActive,
+}
+
+func (e CommentOrder) IsValid() bool {
+	switch e {
+	case CommentOrderOldest, CommentOrderNewest, CommentOrderTop, CommentOrderActive:
+		return true
+	}
+	return false
+}
+
+func (e CommentOrder) String() string {
+	return string(e)
+}
+
+func (e *CommentOrder) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentOrder", str)
+	}
+	return nil
+}
+
+func (e CommentOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type OrderDirection string
+
+const (
+	OrderDirectionAsc  OrderDirection = "ASC"
+	OrderDirectionDesc OrderDirection = "DESC"
+)
+
+var AllOrderDirection = []OrderDirection{
+	OrderDirectionAsc,
+	OrderDirectionDesc,
+}
+
+func (e OrderDirection) IsValid() bool {
+	switch e {
+	case OrderDirectionAsc, OrderDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e OrderDirection) String() string {
+	return string(e)
+}
+
+func (e *OrderDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderDirection", str)
+	}
+	return nil
+}
+
+func (e OrderDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PostOrderField string
+
+const (
+	PostOrderFieldCreatedAt    PostOrderField = "CREATED_AT"
+	PostOrderFieldUpdatedAt    PostOrderField = "UPDATED_AT"
+	PostOrderFieldCommentCount PostOrderField = "COMMENT_COUNT"
+)
+
+var AllPostOrderField = []PostOrderField{
+	PostOrderFieldCreatedAt,
+	PostOrderFieldUpdatedAt,
+	PostOrderFieldCommentCount,
+}
+
+func (e PostOrderField) IsValid() bool {
+	switch e {
+	case PostOrderFieldCreatedAt, PostOrderFieldUpdatedAt, PostOrderFieldCommentCount:
+		return true
+	}
+	return false
+}
+
+func (e PostOrderField) String() string {
+	return string(e)
+}
+
+func (e *PostOrderField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostOrderField", str)
+	}
+	return nil
+}
+
+func (e PostOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+// setStatus applies a moderator decision to the post or comment with the given ID.
+func (r *mutationResolver) setStatus(ctx context.Context, targetType models.TargetType, id string, status models.ContentStatus, reason *string) (models.ModerationItem, error) {
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("moderation rejected", "id", id, "status", status, "error", err)
+		return nil, err
+	}
+	targetID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing target ID", "id", id, "error", err)
+		return nil, err
+	}
+
+	moderator := auth.FromContext(ctx).User
+	if targetType == models.TargetPost {
+		post, err := r.PostService.SetPostStatus(uint(targetID), status, moderator, reason)
+		if err != nil {
+			return nil, err
+		}
+		return post, nil
+	}
+	comment, err := r.PostService.SetCommentStatus(uint(targetID), status, moderator, reason)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// moderationActions returns the moderator decisions about content, which only moderators may read.
+func (r *Resolver) moderationActions(ctx context.Context, targetType models.TargetType, targetID uint) ([]*models.ModerationAction, error) {
+	if auth.Require(ctx, auth.RoleModerator) != nil {
+		return []*models.ModerationAction{}, nil
+	}
+	actions, err := r.PostService.GetModerationActions(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return pointers(actions), nil
+}
+
+// reportedItem loads the post or comment of a report tally. Targets purged since
+// they were reported resolve to null.
+func (r *Resolver) reportedItem(obj *models.ReportedItem) (models.ModerationItem, error) {
+	var item models.ModerationItem
+	var err error
+	if obj.TargetType == models.TargetPost {
+		item, err = r.PostService.GetPostByID(obj.TargetID)
+	} else {
+		item, err = r.PostService.GetCommentByID(obj.TargetID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// mergeQueue orders pending posts and comments by creation time and keeps the first limit items.
+func mergeQueue(posts []models.Post, comments []models.Comment, limit int) []models.ModerationItem {
+	type entry struct {
+		item      models.ModerationItem
+		createdAt time.Time
+	}
+	entries := make([]entry, 0, len(posts)+len(comments))
+	for i := range posts {
+		entries = append(entries, entry{&posts[i], posts[i].CreatedAt})
+	}
+	for i := range comments {
+		entries = append(entries, entry{&comments[i], comments[i].CreatedAt})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].createdAt.Before(entries[j].createdAt)
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	items := make([]models.ModerationItem, len(entries))
+	for i, e := range entries {
+		items[i] = e.item
+	}
+	return items
+}
+
+// listSize returns the number of items requested by a first argument.
+func listSize(first *int, fallback int) (int, error) {
+	if first == nil {
+		return fallback, nil
+	}
+	if *first < 0 {
+		return 0, database.ErrInvalidPageSize
+	}
+	return *first, nil
+}
+
+// setThreadLock locks or unlocks the thread below a comment.
+func (r *mutationResolver) setThreadLock(ctx context.Context, commentID string, locked bool) (*models.Comment, error) {
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("thread lock rejected", "commentID", commentID, "error", err)
+		return nil, err
+	}
+	id, err := strconv.ParseUint(commentID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing comment ID", "commentID", commentID, "error", err)
+		return nil, err
+	}
+	comment, err := r.PostService.SetThreadLock(uint(id), locked, auth.FromContext(ctx).User)
+	if err != nil {
+		slog.Error("error changing thread lock", "commentID", commentID, "error", err)
+		return nil, err
+	}
+	return comment, nil
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+)
+
+func TestMergeQueue(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	post := func(id uint, minutes int) models.Post {
+		return models.Post{ID: id, CreatedAt: at(minutes)}
+	}
+	comment := func(id uint, minutes int) models.Comment {
+		return models.Comment{ID: id, CreatedAt: at(minutes)}
+	}
+
+	tests := []struct {
+		name     string
+		posts    []models.Post
+		comments []models.Comment
+		limit    int
+		want     []string
+	}{
+		{name: "empty", limit: 10, want: []string{}},
+		{
+			name:     "interleaved by creation time",
+			posts:    []models.Post{post(1, 0), post(2, 3)},
+			comments: []models.Comment{comment(1, 1), comment(2, 2), comment(3, 4)},
+			limit:    10,
+			want:     []string{"post 1", "comment 1", "comment 2", "post 2", "comment 3"},
+		},
+		{
+			name:     "limited",
+			posts:    []models.Post{post(1, 0), post(2, 3)},
+			comments: []models.Comment{comment(1, 1), comment(2, 2)},
+			limit:    2,
+			want:     []string{"post 1", "comment 1"},
+		},
+		{
+			name:     "posts first on equal times",
+			posts:    []models.Post{post(1, 0)},
+			comments: []models.Comment{comment(1, 0)},
+			limit:    10,
+			want:     []string{"post 1", "comment 1"},
+		},
+		{
+			name:     "zero limit",
+			posts:    []models.Post{post(1, 0)},
+			comments: []models.Comment{comment(1, 1)},
+			limit:    0,
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, item := range mergeQueue(tt.posts, tt.comments, tt.limit) {
+				switch item := item.(type) {
+				case *models.Post:
+					got = append(got, "post "+strconv.FormatUint(uint64(item.ID), 10))
+				case *models.Comment:
+					got = append(got, "comment "+strconv.FormatUint(uint64(item.ID), 10))
+				default:
+					t.Fatalf("unexpected item %T", item)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeQueue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
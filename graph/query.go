@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/likimiad/ozon_fintech/graph/model"
+	"github.com/likimiad/ozon_fintech/internal/database"
+)
+
+// postSorts maps GraphQL order fields onto PostService sort columns.
+var postSorts = map[model.PostOrderField]database.PostSort{
+	model.PostOrderFieldCreatedAt:    database.SortCreatedAt,
+	model.PostOrderFieldUpdatedAt:    database.SortUpdatedAt,
+	model.PostOrderFieldCommentCount: database.SortCommentCount,
+}
+
+// buildPostQuery converts posts query arguments into a PostService query.
+func buildPostQuery(filter *model.PostFilter, orderBy *model.PostOrder) database.PostQuery {
+	var query database.PostQuery
+
+	if filter != nil {
+		query.Author = filter.Author
+		query.CommentsEnabled = filter.CommentsEnabled
+		query.HasComments = filter.HasComments
+
+		query.CreatedAfter = filter.CreatedAfter
+		query.CreatedBefore = filter.CreatedBefore
+	}
+
+	if orderBy != nil {
+		query.SortBy = postSorts[orderBy.Field]
+		query.Descending = orderBy.Direction == model.OrderDirectionDesc
+	}
+
+	return query
+}
+
+// commentSorts maps GraphQL comment orders onto PostService sort modes.
+var commentSorts = map[model.CommentOrder]database.CommentSort{
+	model.CommentOrderOldest: database.SortOldest,
+	model.CommentOrderNewest: database.SortNewest,
+	model.CommentOrderTop:    database.SortTop,
+	model.CommentOrderActive: database.SortActive,
+}
+
+// buildCommentPage converts comment list arguments into a PostService page.
+func buildCommentPage(orderBy *model.CommentOrder, first *int, after *string) (database.CommentPage, error) {
+	page := database.CommentPage{First: first}
+	if orderBy != nil {
+		page.Sort = commentSorts[*orderBy]
+	}
+	if after != nil {
+		cursor, err := database.ParseCommentCursor(*after)
+		if err != nil {
+			return page, fmt.Errorf("invalid after cursor: %w", err)
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+// parseCursor parses an optional after cursor holding the ID of the last item of the previous page.
+func parseCursor(after *string) (*uint, error) {
+	if after == nil {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(*after, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid after cursor: %w", err)
+	}
+	cursor := uint(id)
+	return &cursor, nil
+}
+
+// pointers converts a slice of values into a slice of pointers to its elements.
+func pointers[T any](items []T) []*T {
+	ptrs := make([]*T, len(items))
+	for i := range items {
+		ptrs[i] = &items[i]
+	}
+	return ptrs
+}
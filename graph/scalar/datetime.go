@@ -0,0 +1,65 @@
+package scalar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+var ErrInvalidDateTime = errors.New("DateTime must be an RFC3339 string")
+
+// layouts holds the named formats accepted by the format argument of DateTime fields.
+// Any other value is used as a Go time layout.
+var layouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC822":      time.RFC822,
+	"Kitchen":     time.Kitchen,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"DateTime":    time.DateTime,
+}
+
+// MarshalDateTime writes a timestamp in RFC3339Nano. Fields declaring format and
+// timeZone arguments are rendered in that layout and IANA time zone instead.
+func MarshalDateTime(t time.Time) graphql.ContextMarshaler {
+	return graphql.ContextWriterFunc(func(ctx context.Context, w io.Writer) error {
+		layout := time.RFC3339Nano
+		if fc := graphql.GetFieldContext(ctx); fc != nil {
+			if format, ok := fc.Args["format"].(*string); ok && format != nil {
+				layout = *format
+				if named, ok := layouts[*format]; ok {
+					layout = named
+				}
+			}
+			if zone, ok := fc.Args["timeZone"].(*string); ok && zone != nil {
+				location, err := time.LoadLocation(*zone)
+				if err != nil {
+					return fmt.Errorf("unknown time zone %q", *zone)
+				}
+				t = t.In(location)
+			}
+		}
+		_, err := io.WriteString(w, strconv.Quote(t.Format(layout)))
+		return err
+	})
+}
+
+// UnmarshalDateTime parses an RFC3339 timestamp, with or without fractional seconds.
+func UnmarshalDateTime(ctx context.Context, v interface{}) (time.Time, error) {
+	value, ok := v.(string)
+	if !ok {
+		return time.Time{}, ErrInvalidDateTime
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDateTime
+	}
+	return t, nil
+}
@@ -6,14 +6,34 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"strconv"
 	"time"
 
 	"github.com/likimiad/ozon_fintech/graph/generated"
+	"github.com/likimiad/ozon_fintech/graph/model"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database"
 	"github.com/likimiad/ozon_fintech/internal/database/models"
-	"log/slog"
+	"github.com/likimiad/ozon_fintech/internal/ratelimit"
+	"gorm.io/gorm"
 )
 
+// ID is the resolver for the id field.
+func (r *banResolver) ID(ctx context.Context, obj *models.Ban) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
+// PostID is the resolver for the postId field.
+func (r *banResolver) PostID(ctx context.Context, obj *models.Ban) (*string, error) {
+	if obj.PostID == nil {
+		return nil, nil
+	}
+	idStr := strconv.FormatUint(uint64(*obj.PostID), 10)
+	return &idStr, nil
+}
+
 // ID is the resolver for the id field.
 func (r *commentResolver) ID(ctx context.Context, obj *models.Comment) (string, error) {
 	return strconv.FormatUint(uint64(obj.ID), 10), nil
@@ -33,63 +53,155 @@ func (r *commentResolver) CommentID(ctx context.Context, obj *models.Comment) (*
 	return &idStr, nil
 }
 
-// CreatedAt is the resolver for the createdAt field.
-func (r *commentResolver) CreatedAt(ctx context.Context, obj *models.Comment) (string, error) {
-	return obj.CreatedAt.Format(time.RFC3339), nil
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *models.Comment) (*models.User, error) {
+	if obj.IsDeleted {
+		return nil, nil
+	}
+	user, err := r.author(ctx, obj.Author)
+	if err != nil {
+		slog.Error("error fetching comment author", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// Content is the resolver for the content field.
+func (r *commentResolver) Content(ctx context.Context, obj *models.Comment) (*string, error) {
+	if obj.IsDeleted {
+		return nil, nil
+	}
+	return &obj.Content, nil
+}
+
+// ReactionCounts is the resolver for the reactionCounts field.
+func (r *commentResolver) ReactionCounts(ctx context.Context, obj *models.Comment) ([]*models.ReactionCount, error) {
+	counts, err := r.PostService.GetReactionCounts(models.TargetComment, obj.ID)
+	if err != nil {
+		slog.Error("error fetching reaction counts", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(counts), nil
+}
+
+// ModerationActions is the resolver for the moderationActions field.
+func (r *commentResolver) ModerationActions(ctx context.Context, obj *models.Comment) ([]*models.ModerationAction, error) {
+	return r.moderationActions(ctx, models.TargetComment, obj.ID)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *models.Comment) ([]*models.Revision, error) {
+	// ? Tombstones keep their history private from readers
+	if obj.IsDeleted && auth.Require(ctx, auth.RoleModerator) != nil {
+		return []*models.Revision{}, nil
+	}
+	revisions, err := r.PostService.GetRevisions(models.TargetComment, obj.ID)
+	if err != nil {
+		slog.Error("error fetching revisions", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(revisions), nil
 }
 
-// UpdatedAt is the resolver for the updatedAt field.
-func (r *commentResolver) UpdatedAt(ctx context.Context, obj *models.Comment) (string, error) {
-	return obj.UpdatedAt.Format(time.RFC3339), nil
+// Cursor is the resolver for the cursor field.
+func (r *commentResolver) Cursor(ctx context.Context, obj *models.Comment, orderBy *model.CommentOrder) (string, error) {
+	page := database.CommentPage{}
+	if orderBy != nil {
+		page.Sort = commentSorts[*orderBy]
+	}
+	return page.CursorOf(obj).String(), nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *models.Comment, orderBy *model.CommentOrder, first *int, after *string) ([]*models.Comment, error) {
+	page, err := buildCommentPage(orderBy, first, after)
+	if err != nil {
+		slog.Error("error parsing replies arguments", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	tree, err := r.commentTree(ctx, obj.PostID)
+	if err != nil {
+		slog.Error("error fetching replies", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	replies, err := tree.Page(&obj.ID, page)
+	if err != nil {
+		slog.Error("error fetching replies", "comment_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(replies), nil
+}
+
+// PostID is the resolver for the postId field.
+func (r *commentsToggledResolver) PostID(ctx context.Context, obj *models.CommentsToggled) (string, error) {
+	return strconv.FormatUint(uint64(obj.PostID), 10), nil
+}
+
+// ID is the resolver for the id field.
+func (r *moderationActionResolver) ID(ctx context.Context, obj *models.ModerationAction) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
 }
 
 // CreatePost is the resolver for the createPost field.
-func (r *mutationResolver) CreatePost(ctx context.Context, title string, content string, author string, commentsEnabled bool) (*models.Post, error) {
-	slog.Info("createPost called", "title", title, "author", author)
+func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePostInput) (*model.CreatePostPayload, error) {
+	slog.Info("createPost called", "title", input.Title, "author", input.Author)
 
 	post := &models.Post{
-		Title:           title,
-		Content:         content,
-		Author:          author,
-		CommentsEnabled: commentsEnabled,
+		Title:           input.Title,
+		Content:         input.Content,
+		Author:          input.Author,
+		CommentsEnabled: input.CommentsEnabled,
+	}
+	var err error
+	if input.IdempotencyKey != nil {
+		post, err = r.PostService.CreatePostOnce(post, *input.IdempotencyKey)
+	} else {
+		err = r.PostService.CreatePost(post)
 	}
-	err := r.PostService.CreatePost(post)
 	if err != nil {
-		slog.Error("error creating post", "error", err)
-		return nil, err
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error creating post", "error", err)
+			return nil, err
+		}
+		return &model.CreatePostPayload{UserErrors: userErrs}, nil
 	}
-	return post, nil
+	return &model.CreatePostPayload{Post: post, UserErrors: []*model.UserError{}}, nil
 }
 
 // UpdatePost is the resolver for the updatePost field.
-func (r *mutationResolver) UpdatePost(ctx context.Context, id string, title *string, content *string, commentsEnabled *bool) (*models.Post, error) {
-	slog.Info("updatePost called", "id", id)
+func (r *mutationResolver) UpdatePost(ctx context.Context, input model.UpdatePostInput) (*model.UpdatePostPayload, error) {
+	slog.Info("updatePost called", "id", input.ID)
 
-	postID, err := strconv.ParseUint(id, 10, 64)
+	postID, err := strconv.ParseUint(input.ID, 10, 64)
 	if err != nil {
-		slog.Error("error parsing post ID", "id", id, "error", err)
+		slog.Error("error parsing post ID", "id", input.ID, "error", err)
 		return nil, err
 	}
 	post, err := r.PostService.GetPostByID(uint(postID))
 	if err != nil {
-		slog.Error("error fetching post", "id", id, "error", err)
+		slog.Error("error fetching post", "id", input.ID, "error", err)
 		return nil, err
 	}
-	if title != nil {
-		post.Title = *title
+	if input.Title != nil {
+		post.Title = *input.Title
 	}
-	if content != nil {
-		post.Content = *content
+	if input.Content != nil {
+		post.Content = *input.Content
 	}
-	if commentsEnabled != nil {
-		post.CommentsEnabled = *commentsEnabled
+	if input.CommentsEnabled != nil {
+		post.CommentsEnabled = *input.CommentsEnabled
 	}
-	err = r.PostService.UpdatePost(post)
+	err = r.PostService.UpdatePost(post, auth.FromContext(ctx), input.ExpectedVersion)
 	if err != nil {
-		slog.Error("error updating post", "id", id, "error", err)
-		return nil, err
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error updating post", "id", input.ID, "error", err)
+			return nil, err
+		}
+		return &model.UpdatePostPayload{UserErrors: userErrs}, nil
 	}
-	return post, nil
+	return &model.UpdatePostPayload{Post: post, UserErrors: []*model.UserError{}}, nil
 }
 
 // DeletePost is the resolver for the deletePost field.
@@ -101,7 +213,7 @@ func (r *mutationResolver) DeletePost(ctx context.Context, id string) (*bool, er
 		slog.Error("error parsing post ID", "id", id, "error", err)
 		return nil, err
 	}
-	err = r.PostService.DeletePost(uint(postID))
+	err = r.PostService.DeletePost(uint(postID), auth.FromContext(ctx))
 	if err != nil {
 		slog.Error("error deleting post", "id", id, "error", err)
 		return nil, err
@@ -110,54 +222,78 @@ func (r *mutationResolver) DeletePost(ctx context.Context, id string) (*bool, er
 	return &success, nil
 }
 
+// RestorePost is the resolver for the restorePost field.
+func (r *mutationResolver) RestorePost(ctx context.Context, id string) (*models.Post, error) {
+	slog.Info("restorePost called", "id", id)
+
+	postID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "id", id, "error", err)
+		return nil, err
+	}
+	post, err := r.PostService.RestorePost(uint(postID), auth.FromContext(ctx))
+	if err != nil {
+		slog.Error("error restoring post", "id", id, "error", err)
+		return nil, err
+	}
+	return post, nil
+}
+
 // CreateComment is the resolver for the createComment field.
-func (r *mutationResolver) CreateComment(ctx context.Context, postID string, commentID *string, author string, content string) (*models.Comment, error) {
-	slog.Info("createComment called", "postID", postID, "author", author)
+func (r *mutationResolver) CreateComment(ctx context.Context, input model.CreateCommentInput) (*model.CreateCommentPayload, error) {
+	slog.Info("createComment called", "postID", input.PostID, "author", input.Author)
 
-	postIDUint, err := strconv.ParseUint(postID, 10, 64)
+	postID, err := strconv.ParseUint(input.PostID, 10, 64)
 	if err != nil {
-		slog.Error("error parsing post ID", "postID", postID, "error", err)
+		slog.Error("error parsing post ID", "postID", input.PostID, "error", err)
 		return nil, err
 	}
 	var parentID *uint
-	if commentID != nil {
-		id, err := strconv.ParseUint(*commentID, 10, 64)
+	if input.CommentID != nil {
+		id, err := strconv.ParseUint(*input.CommentID, 10, 64)
 		if err != nil {
-			slog.Error("error parsing comment ID", "commentID", *commentID, "error", err)
+			slog.Error("error parsing comment ID", "commentID", *input.CommentID, "error", err)
 			return nil, err
 		}
 		tmp := uint(id)
 		parentID = &tmp
 	}
-	comment, err := r.PostService.CreateComment(uint(postIDUint), parentID, author, content)
-	if err != nil {
-		slog.Error("error creating comment", "error", err)
-		return nil, err
+	var comment *models.Comment
+	if input.IdempotencyKey != nil {
+		comment, err = r.PostService.CreateCommentOnce(uint(postID), parentID, input.Author, input.Content, *input.IdempotencyKey, auth.FromContext(ctx))
+	} else {
+		comment, err = r.PostService.CreateComment(uint(postID), parentID, input.Author, input.Content, auth.FromContext(ctx))
 	}
-	// Notify subscribers about the new comment
-	r.mu.Lock()
-	if ch, ok := r.subscriptions[uint(postIDUint)]; ok {
-		ch <- comment
+	if err != nil {
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error creating comment", "error", err)
+			return nil, err
+		}
+		return &model.CreateCommentPayload{UserErrors: userErrs}, nil
 	}
-	r.mu.Unlock()
-	return comment, nil
+	return &model.CreateCommentPayload{Comment: comment, UserErrors: []*model.UserError{}}, nil
 }
 
 // UpdateComment is the resolver for the updateComment field.
-func (r *mutationResolver) UpdateComment(ctx context.Context, id string, content string) (*models.Comment, error) {
-	slog.Info("updateComment called", "id", id)
+func (r *mutationResolver) UpdateComment(ctx context.Context, input model.UpdateCommentInput) (*model.UpdateCommentPayload, error) {
+	slog.Info("updateComment called", "id", input.ID)
 
-	commentID, err := strconv.ParseUint(id, 10, 64)
+	commentID, err := strconv.ParseUint(input.ID, 10, 64)
 	if err != nil {
-		slog.Error("error parsing comment ID", "id", id, "error", err)
+		slog.Error("error parsing comment ID", "id", input.ID, "error", err)
 		return nil, err
 	}
-	comment, err := r.PostService.UpdateComment(uint(commentID), content)
+	comment, err := r.PostService.UpdateComment(uint(commentID), input.Content, auth.FromContext(ctx), input.ExpectedVersion)
 	if err != nil {
-		slog.Error("error updating comment", "id", id, "error", err)
-		return nil, err
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error updating comment", "id", input.ID, "error", err)
+			return nil, err
+		}
+		return &model.UpdateCommentPayload{UserErrors: userErrs}, nil
 	}
-	return comment, nil
+	return &model.UpdateCommentPayload{Comment: comment, UserErrors: []*model.UserError{}}, nil
 }
 
 // DeleteComment is the resolver for the deleteComment field.
@@ -169,7 +305,7 @@ func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (*bool,
 		slog.Error("error parsing comment ID", "id", id, "error", err)
 		return nil, err
 	}
-	err = r.PostService.DeleteComment(uint(commentID))
+	err = r.PostService.DeleteComment(uint(commentID), auth.FromContext(ctx))
 	if err != nil {
 		slog.Error("error deleting comment", "id", id, "error", err)
 		return nil, err
@@ -178,26 +314,424 @@ func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (*bool,
 	return &success, nil
 }
 
+// React is the resolver for the react field.
+func (r *mutationResolver) React(ctx context.Context, targetType models.TargetType, targetID string, kind models.ReactionKind) (*models.ReactionSummary, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("react called", "targetType", targetType, "targetID", targetID, "user", viewer.User, "kind", kind)
+
+	if viewer.User == "" {
+		return nil, auth.ErrForbidden
+	}
+	id, err := strconv.ParseUint(targetID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing target ID", "targetID", targetID, "error", err)
+		return nil, err
+	}
+	summary, err := r.PostService.React(targetType, uint(id), viewer.User, kind)
+	if err != nil {
+		slog.Error("error updating reactions", "targetID", targetID, "error", err)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Unreact is the resolver for the unreact field.
+func (r *mutationResolver) Unreact(ctx context.Context, targetType models.TargetType, targetID string, kind models.ReactionKind) (*models.ReactionSummary, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("unreact called", "targetType", targetType, "targetID", targetID, "user", viewer.User, "kind", kind)
+
+	if viewer.User == "" {
+		return nil, auth.ErrForbidden
+	}
+	id, err := strconv.ParseUint(targetID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing target ID", "targetID", targetID, "error", err)
+		return nil, err
+	}
+	summary, err := r.PostService.Unreact(targetType, uint(id), viewer.User, kind)
+	if err != nil {
+		slog.Error("error updating reactions", "targetID", targetID, "error", err)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// RestorePostRevision is the resolver for the restorePostRevision field.
+func (r *mutationResolver) RestorePostRevision(ctx context.Context, id string) (*models.Post, error) {
+	slog.Info("restorePostRevision called", "id", id)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("restorePostRevision rejected", "id", id, "error", err)
+		return nil, err
+	}
+	revisionID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing revision ID", "id", id, "error", err)
+		return nil, err
+	}
+	restored, err := r.PostService.RestorePostRevision(uint(revisionID), auth.FromContext(ctx))
+	if err != nil {
+		slog.Error("error restoring revision", "id", id, "error", err)
+		return nil, err
+	}
+	return restored, nil
+}
+
+// RestoreCommentRevision is the resolver for the restoreCommentRevision field.
+func (r *mutationResolver) RestoreCommentRevision(ctx context.Context, id string) (*models.Comment, error) {
+	slog.Info("restoreCommentRevision called", "id", id)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("restoreCommentRevision rejected", "id", id, "error", err)
+		return nil, err
+	}
+	revisionID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing revision ID", "id", id, "error", err)
+		return nil, err
+	}
+	restored, err := r.PostService.RestoreCommentRevision(uint(revisionID), auth.FromContext(ctx))
+	if err != nil {
+		slog.Error("error restoring revision", "id", id, "error", err)
+		return nil, err
+	}
+	return restored, nil
+}
+
+// Approve is the resolver for the approve field.
+func (r *mutationResolver) Approve(ctx context.Context, targetType models.TargetType, id string, reason *string) (models.ModerationItem, error) {
+	slog.Info("approve called", "targetType", targetType, "id", id)
+	return r.setStatus(ctx, targetType, id, models.StatusPublished, reason)
+}
+
+// Reject is the resolver for the reject field.
+func (r *mutationResolver) Reject(ctx context.Context, targetType models.TargetType, id string, reason *string) (models.ModerationItem, error) {
+	slog.Info("reject called", "targetType", targetType, "id", id)
+	return r.setStatus(ctx, targetType, id, models.StatusRejected, reason)
+}
+
+// Hide is the resolver for the hide field.
+func (r *mutationResolver) Hide(ctx context.Context, targetType models.TargetType, id string, reason *string) (models.ModerationItem, error) {
+	slog.Info("hide called", "targetType", targetType, "id", id)
+	return r.setStatus(ctx, targetType, id, models.StatusHidden, reason)
+}
+
+// Report is the resolver for the report field.
+func (r *mutationResolver) Report(ctx context.Context, targetType models.TargetType, targetID string, reason models.ReportReason, note *string) (bool, error) {
+	slog.Info("report called", "targetType", targetType, "targetID", targetID, "reason", reason)
+
+	id, err := strconv.ParseUint(targetID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing target ID", "targetID", targetID, "error", err)
+		return false, err
+	}
+	err = r.PostService.Report(targetType, uint(id), auth.FromContext(ctx).User, ratelimit.ClientIP(ctx), reason, note)
+	if err != nil {
+		slog.Error("error reporting content", "targetID", targetID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// BanAuthor is the resolver for the banAuthor field.
+func (r *mutationResolver) BanAuthor(ctx context.Context, author string, postID *string, expiresAt *time.Time, reason *string) (*models.Ban, error) {
+	slog.Info("banAuthor called", "author", author, "postID", postID)
+
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("banAuthor rejected", "author", author, "error", err)
+		return nil, err
+	}
+	var post *uint
+	if postID != nil {
+		id, err := strconv.ParseUint(*postID, 10, 64)
+		if err != nil {
+			slog.Error("error parsing post ID", "postID", *postID, "error", err)
+			return nil, err
+		}
+		tmp := uint(id)
+		post = &tmp
+	}
+	ban, err := r.PostService.BanAuthor(author, post, expiresAt, reason, auth.FromContext(ctx).User)
+	if err != nil {
+		slog.Error("error banning author", "author", author, "error", err)
+		return nil, err
+	}
+	return ban, nil
+}
+
+// LiftBan is the resolver for the liftBan field.
+func (r *mutationResolver) LiftBan(ctx context.Context, id string) (bool, error) {
+	slog.Info("liftBan called", "id", id)
+
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("liftBan rejected", "id", id, "error", err)
+		return false, err
+	}
+	banID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing ban ID", "id", id, "error", err)
+		return false, err
+	}
+	if err := r.PostService.LiftBan(uint(banID), auth.FromContext(ctx).User); err != nil {
+		slog.Error("error lifting ban", "id", id, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// LockThread is the resolver for the lockThread field.
+func (r *mutationResolver) LockThread(ctx context.Context, commentID string) (*models.Comment, error) {
+	slog.Info("lockThread called", "commentID", commentID)
+	return r.setThreadLock(ctx, commentID, true)
+}
+
+// UnlockThread is the resolver for the unlockThread field.
+func (r *mutationResolver) UnlockThread(ctx context.Context, commentID string) (*models.Comment, error) {
+	slog.Info("unlockThread called", "commentID", commentID)
+	return r.setThreadLock(ctx, commentID, false)
+}
+
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.UpdateProfileInput) (*model.UpdateProfilePayload, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("updateProfile called", "user", viewer.User)
+
+	if viewer.User == "" {
+		return nil, auth.ErrForbidden
+	}
+	user, err := r.PostService.UpdateProfile(viewer.User, input.DisplayName, input.Bio, input.AvatarURL)
+	if err != nil {
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error updating profile", "error", err)
+			return nil, err
+		}
+		return &model.UpdateProfilePayload{UserErrors: userErrs}, nil
+	}
+	return &model.UpdateProfilePayload{User: user, UserErrors: []*model.UserError{}}, nil
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("markNotificationsRead called", "user", viewer.User, "ids", ids)
+
+	if viewer.User == "" {
+		return 0, auth.ErrForbidden
+	}
+	var notificationIDs []uint
+	if ids != nil {
+		notificationIDs = make([]uint, len(ids))
+		for i, id := range ids {
+			idUint, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				slog.Error("error parsing notification ID", "id", id, "error", err)
+				return 0, err
+			}
+			notificationIDs[i] = uint(idUint)
+		}
+	}
+	marked, err := r.PostService.MarkNotificationsRead(viewer.User, notificationIDs)
+	if err != nil {
+		slog.Error("error marking notifications read", "error", err)
+		return 0, err
+	}
+	return int(marked), nil
+}
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input model.CreateWebhookInput) (*model.CreateWebhookPayload, error) {
+	slog.Info("createWebhook called", "url", input.URL)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("createWebhook rejected", "error", err)
+		return nil, err
+	}
+	hook, err := r.PostService.CreateWebhook(input.URL, input.Events, auth.FromContext(ctx).User)
+	if err != nil {
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error creating webhook", "error", err)
+			return nil, err
+		}
+		return &model.CreateWebhookPayload{UserErrors: userErrs}, nil
+	}
+	return &model.CreateWebhookPayload{Webhook: hook, Secret: &hook.Secret, UserErrors: []*model.UserError{}}, nil
+}
+
+// UpdateWebhook is the resolver for the updateWebhook field.
+func (r *mutationResolver) UpdateWebhook(ctx context.Context, input model.UpdateWebhookInput) (*model.UpdateWebhookPayload, error) {
+	slog.Info("updateWebhook called", "id", input.ID)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("updateWebhook rejected", "id", input.ID, "error", err)
+		return nil, err
+	}
+	webhookID, err := strconv.ParseUint(input.ID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing webhook ID", "id", input.ID, "error", err)
+		return nil, err
+	}
+	hook, err := r.PostService.UpdateWebhook(uint(webhookID), input.URL, input.Events, input.Active)
+	if err != nil {
+		userErrs, err := userErrors(err)
+		if err != nil {
+			slog.Error("error updating webhook", "id", input.ID, "error", err)
+			return nil, err
+		}
+		return &model.UpdateWebhookPayload{UserErrors: userErrs}, nil
+	}
+	return &model.UpdateWebhookPayload{Webhook: hook, UserErrors: []*model.UserError{}}, nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	slog.Info("deleteWebhook called", "id", id)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("deleteWebhook rejected", "id", id, "error", err)
+		return false, err
+	}
+	webhookID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing webhook ID", "id", id, "error", err)
+		return false, err
+	}
+	if err := r.PostService.DeleteWebhook(uint(webhookID)); err != nil {
+		slog.Error("error deleting webhook", "id", id, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// RetryWebhookDelivery is the resolver for the retryWebhookDelivery field.
+func (r *mutationResolver) RetryWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	slog.Info("retryWebhookDelivery called", "id", id)
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("retryWebhookDelivery rejected", "id", id, "error", err)
+		return nil, err
+	}
+	deliveryID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing delivery ID", "id", id, "error", err)
+		return nil, err
+	}
+	delivery, err := r.PostService.RetryWebhookDelivery(uint(deliveryID))
+	if err != nil {
+		slog.Error("error retrying webhook delivery", "id", id, "error", err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ID is the resolver for the id field.
+func (r *notificationResolver) ID(ctx context.Context, obj *models.Notification) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
+// Actor is the resolver for the actor field.
+func (r *notificationResolver) Actor(ctx context.Context, obj *models.Notification) (*models.User, error) {
+	user, err := r.author(ctx, obj.Actor)
+	if err != nil {
+		slog.Error("error fetching notification actor", "notification_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// TargetID is the resolver for the targetId field.
+func (r *notificationResolver) TargetID(ctx context.Context, obj *models.Notification) (string, error) {
+	return strconv.FormatUint(uint64(obj.TargetID), 10), nil
+}
+
+// PostID is the resolver for the postId field.
+func (r *notificationResolver) PostID(ctx context.Context, obj *models.Notification) (string, error) {
+	return strconv.FormatUint(uint64(obj.PostID), 10), nil
+}
+
 // ID is the resolver for the id field.
 func (r *postResolver) ID(ctx context.Context, obj *models.Post) (string, error) {
 	return strconv.FormatUint(uint64(obj.ID), 10), nil
 }
 
-// CreatedAt is the resolver for the createdAt field.
-func (r *postResolver) CreatedAt(ctx context.Context, obj *models.Post) (string, error) {
-	return obj.CreatedAt.Format(time.RFC3339), nil
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *models.Post) (*models.User, error) {
+	user, err := r.author(ctx, obj.Author)
+	if err != nil {
+		slog.Error("error fetching post author", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// ReactionCounts is the resolver for the reactionCounts field.
+func (r *postResolver) ReactionCounts(ctx context.Context, obj *models.Post) ([]*models.ReactionCount, error) {
+	counts, err := r.PostService.GetReactionCounts(models.TargetPost, obj.ID)
+	if err != nil {
+		slog.Error("error fetching reaction counts", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(counts), nil
+}
+
+// ModerationActions is the resolver for the moderationActions field.
+func (r *postResolver) ModerationActions(ctx context.Context, obj *models.Post) ([]*models.ModerationAction, error) {
+	return r.moderationActions(ctx, models.TargetPost, obj.ID)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *postResolver) Revisions(ctx context.Context, obj *models.Post) ([]*models.Revision, error) {
+	revisions, err := r.PostService.GetRevisions(models.TargetPost, obj.ID)
+	if err != nil {
+		slog.Error("error fetching revisions", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(revisions), nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *postResolver) Comments(ctx context.Context, obj *models.Post, orderBy *model.CommentOrder, first *int, after *string) ([]*models.Comment, error) {
+	page, err := buildCommentPage(orderBy, first, after)
+	if err != nil {
+		slog.Error("error parsing comments arguments", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	tree, err := r.commentTree(ctx, obj.ID)
+	if err != nil {
+		slog.Error("error fetching comments", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	comments, err := tree.Page(nil, page)
+	if err != nil {
+		slog.Error("error fetching comments", "post_id", obj.ID, "error", err)
+		return nil, err
+	}
+	return pointers(comments), nil
 }
 
-// UpdatedAt is the resolver for the updatedAt field.
-func (r *postResolver) UpdatedAt(ctx context.Context, obj *models.Post) (string, error) {
-	return obj.UpdatedAt.Format(time.RFC3339), nil
+// DeletedAt is the resolver for the deletedAt field.
+func (r *postResolver) DeletedAt(ctx context.Context, obj *models.Post, format *string, timeZone *string) (*time.Time, error) {
+	if !obj.DeletedAt.Valid {
+		return nil, nil
+	}
+	return &obj.DeletedAt.Time, nil
 }
 
 // Posts is the resolver for the posts field.
-func (r *queryResolver) Posts(ctx context.Context) ([]*models.Post, error) {
+func (r *queryResolver) Posts(ctx context.Context, filter *model.PostFilter, orderBy *model.PostOrder, includeDeleted *bool) ([]*models.Post, error) {
 	slog.Info("posts query called")
 
-	posts, err := r.PostService.GetPosts()
+	query := buildPostQuery(filter, orderBy)
+	if includeDeleted != nil && *includeDeleted {
+		if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+			slog.Warn("includeDeleted rejected", "error", err)
+			return nil, err
+		}
+		query.IncludeDeleted = true
+	}
+
+	posts, err := r.PostService.GetPosts(query, auth.FromContext(ctx))
 	if err != nil {
 		slog.Error("error fetching posts", "error", err)
 		return nil, err
@@ -226,11 +760,178 @@ func (r *queryResolver) Post(ctx context.Context, id string) (*models.Post, erro
 		slog.Error("error fetching post", "id", id, "error", err)
 		return nil, err
 	}
+	if !database.CanView(auth.FromContext(ctx), post.Author, post.Status) {
+		return nil, nil
+	}
 	return post, nil
 }
 
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, first *int) ([]models.ModerationItem, error) {
+	slog.Info("moderationQueue query called")
+
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("moderationQueue rejected", "error", err)
+		return nil, err
+	}
+	limit, err := listSize(first, 50)
+	if err != nil {
+		return nil, err
+	}
+	posts, comments, err := r.PostService.GetModerationQueue(limit)
+	if err != nil {
+		slog.Error("error fetching moderation queue", "error", err)
+		return nil, err
+	}
+	return mergeQueue(posts, comments, limit), nil
+}
+
+// MostReported is the resolver for the mostReported field.
+func (r *queryResolver) MostReported(ctx context.Context, targetType *models.TargetType, first *int) ([]*models.ReportedItem, error) {
+	slog.Info("mostReported query called")
+
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("mostReported rejected", "error", err)
+		return nil, err
+	}
+	limit, err := listSize(first, 20)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.PostService.GetMostReported(targetType, limit)
+	if err != nil {
+		slog.Error("error fetching most reported content", "error", err)
+		return nil, err
+	}
+	return pointers(items), nil
+}
+
+// Bans is the resolver for the bans field.
+func (r *queryResolver) Bans(ctx context.Context, author *string) ([]*models.Ban, error) {
+	slog.Info("bans query called")
+
+	if err := auth.Require(ctx, auth.RoleModerator); err != nil {
+		slog.Warn("bans rejected", "error", err)
+		return nil, err
+	}
+	bans, err := r.PostService.GetBans(author)
+	if err != nil {
+		slog.Error("error fetching bans", "error", err)
+		return nil, err
+	}
+	return pointers(bans), nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, handle string) (*models.User, error) {
+	slog.Info("user query called", "handle", handle)
+
+	user, err := r.PostService.GetUserByHandle(handle)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("error fetching user", "handle", handle, "error", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, first *int, after *string, unreadOnly *bool) ([]*models.Notification, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("notifications query called", "user", viewer.User)
+
+	if viewer.User == "" {
+		return nil, auth.ErrForbidden
+	}
+	cursor, err := parseCursor(after)
+	if err != nil {
+		slog.Error("error parsing notifications arguments", "error", err)
+		return nil, err
+	}
+	notifications, err := r.PostService.GetNotifications(viewer.User, first, cursor, unreadOnly != nil && *unreadOnly)
+	if err != nil {
+		slog.Error("error fetching notifications", "error", err)
+		return nil, err
+	}
+	return pointers(notifications), nil
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*models.Webhook, error) {
+	slog.Info("webhooks query called")
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("webhooks rejected", "error", err)
+		return nil, err
+	}
+	hooks, err := r.PostService.GetWebhooks()
+	if err != nil {
+		slog.Error("error fetching webhooks", "error", err)
+		return nil, err
+	}
+	return pointers(hooks), nil
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID *string, status *models.DeliveryStatus, first *int, after *string) ([]*models.WebhookDelivery, error) {
+	slog.Info("webhookDeliveries query called")
+
+	if err := auth.Require(ctx, auth.RoleAdmin); err != nil {
+		slog.Warn("webhookDeliveries rejected", "error", err)
+		return nil, err
+	}
+	var hookID *uint
+	if webhookID != nil {
+		id, err := strconv.ParseUint(*webhookID, 10, 64)
+		if err != nil {
+			slog.Error("error parsing webhook ID", "id", *webhookID, "error", err)
+			return nil, err
+		}
+		idUint := uint(id)
+		hookID = &idUint
+	}
+	cursor, err := parseCursor(after)
+	if err != nil {
+		slog.Error("error parsing webhookDeliveries arguments", "error", err)
+		return nil, err
+	}
+	deliveries, err := r.PostService.GetWebhookDeliveries(hookID, status, first, cursor)
+	if err != nil {
+		slog.Error("error fetching webhook deliveries", "error", err)
+		return nil, err
+	}
+	return pointers(deliveries), nil
+}
+
+// TargetID is the resolver for the targetId field.
+func (r *reactionSummaryResolver) TargetID(ctx context.Context, obj *models.ReactionSummary) (string, error) {
+	return strconv.FormatUint(uint64(obj.TargetID), 10), nil
+}
+
+// PostID is the resolver for the postId field.
+func (r *reactionSummaryResolver) PostID(ctx context.Context, obj *models.ReactionSummary) (string, error) {
+	return strconv.FormatUint(uint64(obj.PostID), 10), nil
+}
+
+// TargetID is the resolver for the targetId field.
+func (r *reportedItemResolver) TargetID(ctx context.Context, obj *models.ReportedItem) (string, error) {
+	return strconv.FormatUint(uint64(obj.TargetID), 10), nil
+}
+
+// Item is the resolver for the item field.
+func (r *reportedItemResolver) Item(ctx context.Context, obj *models.ReportedItem) (models.ModerationItem, error) {
+	return r.reportedItem(obj)
+}
+
+// ID is the resolver for the id field.
+func (r *revisionResolver) ID(ctx context.Context, obj *models.Revision) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
 // CommentAdded is the resolver for the commentAdded field.
-func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string) (<-chan *models.Comment, error) {
+func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string, sinceSequence *int) (<-chan *models.Comment, error) {
 	slog.Info("commentAdded subscription called", "postID", postID)
 
 	postIDUint, err := strconv.ParseUint(postID, 10, 64)
@@ -238,30 +939,216 @@ func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string)
 		slog.Error("error parsing post ID", "postID", postID, "error", err)
 		return nil, err
 	}
-	r.mu.Lock()
-	ch := make(chan *models.Comment, 1)
-	r.subscriptions[uint(postIDUint)] = ch
-	r.mu.Unlock()
-	return ch, nil
+	events, err := r.PostService.SubscribeCommentEvents(ctx, uint(postIDUint), sequence(ctx, sinceSequence))
+	if err != nil {
+		slog.Error("error subscribing to comment events", "postID", postID, "error", err)
+		return nil, err
+	}
+	return commentsAdded(ctx, events), nil
+}
+
+// CommentEvents is the resolver for the commentEvents field.
+func (r *subscriptionResolver) CommentEvents(ctx context.Context, postID string, sinceSequence *int) (<-chan models.CommentEvent, error) {
+	slog.Info("commentEvents subscription called", "postID", postID)
+
+	postIDUint, err := strconv.ParseUint(postID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "postID", postID, "error", err)
+		return nil, err
+	}
+	events, err := r.PostService.SubscribeCommentEvents(ctx, uint(postIDUint), sequence(ctx, sinceSequence))
+	if err != nil {
+		slog.Error("error subscribing to comment events", "postID", postID, "error", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// ReactionsChanged is the resolver for the reactionsChanged field.
+func (r *subscriptionResolver) ReactionsChanged(ctx context.Context, postID string) (<-chan *models.ReactionSummary, error) {
+	slog.Info("reactionsChanged subscription called", "postID", postID)
+
+	postIDUint, err := strconv.ParseUint(postID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "postID", postID, "error", err)
+		return nil, err
+	}
+	return r.PostService.ReactionsChanged.Subscribe(ctx, strconv.FormatUint(postIDUint, 10)), nil
+}
+
+// PostCreated is the resolver for the postCreated field.
+func (r *subscriptionResolver) PostCreated(ctx context.Context) (<-chan *models.Post, error) {
+	slog.Info("postCreated subscription called")
+
+	events := r.PostService.PostEvents.Subscribe(ctx, database.AllPostsTopic)
+	return postsCreated(ctx, events), nil
+}
+
+// PostUpdated is the resolver for the postUpdated field.
+func (r *subscriptionResolver) PostUpdated(ctx context.Context, id string) (<-chan *models.Post, error) {
+	slog.Info("postUpdated subscription called", "id", id)
+
+	postID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "id", id, "error", err)
+		return nil, err
+	}
+	events := r.PostService.PostEvents.Subscribe(ctx, strconv.FormatUint(postID, 10))
+	return postsUpdated(ctx, events), nil
+}
+
+// PostDeleted is the resolver for the postDeleted field.
+func (r *subscriptionResolver) PostDeleted(ctx context.Context, id string) (<-chan *models.Post, error) {
+	slog.Info("postDeleted subscription called", "id", id)
+
+	postID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "id", id, "error", err)
+		return nil, err
+	}
+	events := r.PostService.PostEvents.Subscribe(ctx, strconv.FormatUint(postID, 10))
+	return postsDeleted(ctx, events), nil
 }
 
+// CommentsToggled is the resolver for the commentsToggled field.
+func (r *subscriptionResolver) CommentsToggled(ctx context.Context, postID string) (<-chan *models.CommentsToggled, error) {
+	slog.Info("commentsToggled subscription called", "postID", postID)
+
+	postIDUint, err := strconv.ParseUint(postID, 10, 64)
+	if err != nil {
+		slog.Error("error parsing post ID", "postID", postID, "error", err)
+		return nil, err
+	}
+	events := r.PostService.PostEvents.Subscribe(ctx, strconv.FormatUint(postIDUint, 10))
+	return commentsToggled(ctx, events), nil
+}
+
+// NotificationAdded is the resolver for the notificationAdded field.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context) (<-chan *models.Notification, error) {
+	viewer := auth.FromContext(ctx)
+	slog.Info("notificationAdded subscription called", "user", viewer.User)
+
+	if viewer.User == "" {
+		return nil, auth.ErrForbidden
+	}
+	return r.PostService.Notifications.Subscribe(ctx, viewer.User), nil
+}
+
+// ID is the resolver for the id field.
+func (r *userResolver) ID(ctx context.Context, obj *models.User) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
+// Posts is the resolver for the posts field.
+func (r *userResolver) Posts(ctx context.Context, obj *models.User, first *int, after *string) ([]*models.Post, error) {
+	cursor, err := parseCursor(after)
+	if err != nil {
+		slog.Error("error parsing posts arguments", "handle", obj.Handle, "error", err)
+		return nil, err
+	}
+	posts, err := r.PostService.GetUserPosts(obj.Handle, auth.FromContext(ctx), first, cursor)
+	if err != nil {
+		slog.Error("error fetching user posts", "handle", obj.Handle, "error", err)
+		return nil, err
+	}
+	return pointers(posts), nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *userResolver) Comments(ctx context.Context, obj *models.User, first *int, after *string) ([]*models.Comment, error) {
+	cursor, err := parseCursor(after)
+	if err != nil {
+		slog.Error("error parsing comments arguments", "handle", obj.Handle, "error", err)
+		return nil, err
+	}
+	comments, err := r.PostService.GetUserComments(obj.Handle, auth.FromContext(ctx), first, cursor)
+	if err != nil {
+		slog.Error("error fetching user comments", "handle", obj.Handle, "error", err)
+		return nil, err
+	}
+	return pointers(comments), nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookResolver) ID(ctx context.Context, obj *models.Webhook) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
+// Events is the resolver for the events field.
+func (r *webhookResolver) Events(ctx context.Context, obj *models.Webhook) ([]models.WebhookEvent, error) {
+	return obj.Events, nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookDeliveryResolver) ID(ctx context.Context, obj *models.WebhookDelivery) (string, error) {
+	return strconv.FormatUint(uint64(obj.ID), 10), nil
+}
+
+// Ban returns generated.BanResolver implementation.
+func (r *Resolver) Ban() generated.BanResolver { return &banResolver{r} }
+
 // Comment returns generated.CommentResolver implementation.
 func (r *Resolver) Comment() generated.CommentResolver { return &commentResolver{r} }
 
+// CommentsToggled returns generated.CommentsToggledResolver implementation.
+func (r *Resolver) CommentsToggled() generated.CommentsToggledResolver {
+	return &commentsToggledResolver{r}
+}
+
+// ModerationAction returns generated.ModerationActionResolver implementation.
+func (r *Resolver) ModerationAction() generated.ModerationActionResolver {
+	return &moderationActionResolver{r}
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// Notification returns generated.NotificationResolver implementation.
+func (r *Resolver) Notification() generated.NotificationResolver { return &notificationResolver{r} }
+
 // Post returns generated.PostResolver implementation.
 func (r *Resolver) Post() generated.PostResolver { return &postResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// ReactionSummary returns generated.ReactionSummaryResolver implementation.
+func (r *Resolver) ReactionSummary() generated.ReactionSummaryResolver {
+	return &reactionSummaryResolver{r}
+}
+
+// ReportedItem returns generated.ReportedItemResolver implementation.
+func (r *Resolver) ReportedItem() generated.ReportedItemResolver { return &reportedItemResolver{r} }
+
+// Revision returns generated.RevisionResolver implementation.
+func (r *Resolver) Revision() generated.RevisionResolver { return &revisionResolver{r} }
+
 // Subscription returns generated.SubscriptionResolver implementation.
 func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
 
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
+// Webhook returns generated.WebhookResolver implementation.
+func (r *Resolver) Webhook() generated.WebhookResolver { return &webhookResolver{r} }
+
+// WebhookDelivery returns generated.WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() generated.WebhookDeliveryResolver {
+	return &webhookDeliveryResolver{r}
+}
+
+type banResolver struct{ *Resolver }
 type commentResolver struct{ *Resolver }
+type commentsToggledResolver struct{ *Resolver }
+type moderationActionResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
 type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type reactionSummaryResolver struct{ *Resolver }
+type reportedItemResolver struct{ *Resolver }
+type revisionResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type webhookResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/likimiad/ozon_fintech/internal/database"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/sse"
+)
+
+// forward relays the events accepted by pick until ctx is done.
+func forward[E, T any](ctx context.Context, events <-chan E, pick func(E) (T, bool)) <-chan T {
+	out := make(chan T, database.SubscriptionBuffer)
+	go func() {
+		defer close(out)
+		for event := range events {
+			value, ok := pick(event)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// commentsAdded forwards the comments of CommentAdded events until ctx is done.
+func commentsAdded(ctx context.Context, events <-chan models.CommentEvent) <-chan *models.Comment {
+	return forward(ctx, events, func(event models.CommentEvent) (*models.Comment, bool) {
+		added, ok := event.(*models.CommentAdded)
+		if !ok {
+			return nil, false
+		}
+		return added.Comment, true
+	})
+}
+
+// withEventID returns a copy of a post carrying the ID of the event that delivered it.
+// The post of an event is shared by every subscriber, so it is not changed in place.
+func withEventID(post *models.Post, eventID string) *models.Post {
+	delivered := *post
+	delivered.EventID = &eventID
+	return &delivered
+}
+
+// postsCreated forwards the posts of PostCreated events until ctx is done.
+func postsCreated(ctx context.Context, events <-chan models.PostEvent) <-chan *models.Post {
+	return forward(ctx, events, func(event models.PostEvent) (*models.Post, bool) {
+		created, ok := event.(*models.PostCreated)
+		if !ok {
+			return nil, false
+		}
+		return withEventID(created.Post, created.EventID), true
+	})
+}
+
+// postsUpdated forwards the posts of PostUpdated events until ctx is done.
+func postsUpdated(ctx context.Context, events <-chan models.PostEvent) <-chan *models.Post {
+	return forward(ctx, events, func(event models.PostEvent) (*models.Post, bool) {
+		updated, ok := event.(*models.PostUpdated)
+		if !ok {
+			return nil, false
+		}
+		return withEventID(updated.Post, updated.EventID), true
+	})
+}
+
+// postsDeleted forwards the posts of PostDeleted events until ctx is done.
+func postsDeleted(ctx context.Context, events <-chan models.PostEvent) <-chan *models.Post {
+	return forward(ctx, events, func(event models.PostEvent) (*models.Post, bool) {
+		deleted, ok := event.(*models.PostDeleted)
+		if !ok {
+			return nil, false
+		}
+		return withEventID(deleted.Post, deleted.EventID), true
+	})
+}
+
+// commentsToggled forwards CommentsToggled events until ctx is done.
+func commentsToggled(ctx context.Context, events <-chan models.PostEvent) <-chan *models.CommentsToggled {
+	return forward(ctx, events, func(event models.PostEvent) (*models.CommentsToggled, bool) {
+		toggled, ok := event.(*models.CommentsToggled)
+		return toggled, ok
+	})
+}
+
+// sequence converts an optional sinceSequence argument into an event sequence.
+// Without the argument, the last event ID of a reconnecting SSE client is used.
+func sequence(ctx context.Context, since *int) *int64 {
+	if since == nil {
+		if id, ok := sse.LastEventID(ctx); ok {
+			return &id
+		}
+		return nil
+	}
+	value := int64(*since)
+	return &value
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/likimiad/ozon_fintech/internal/config"
+)
+
+// Role is the access level of a viewer. Higher roles include lower ones.
+type Role int
+
+const (
+	RoleUser Role = iota
+	RoleModerator
+	RoleAdmin
+)
+
+var (
+	ErrForbidden    = errors.New("not allowed to perform this action")
+	ErrInvalidToken = errors.New("invalid authorization token")
+)
+
+// Viewer identifies who performs a request.
+type Viewer struct {
+	User string // ? Handle sent by the client, empty for anonymous requests
+	Role Role
+}
+
+type viewerKey struct{}
+
+// WithViewer returns a context carrying the viewer.
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+// FromContext returns the viewer of the request, or an anonymous user.
+func FromContext(ctx context.Context) Viewer {
+	viewer, _ := ctx.Value(viewerKey{}).(Viewer)
+	return viewer
+}
+
+// Require returns ErrForbidden unless the viewer has at least the given role.
+func Require(ctx context.Context, role Role) error {
+	if FromContext(ctx).Role < role {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Authenticator resolves viewers from user handles and bearer tokens.
+type Authenticator struct {
+	adminToken     string
+	moderatorToken string
+}
+
+// NewAuthenticator creates an Authenticator from the auth configuration.
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	return &Authenticator{
+		adminToken:     cfg.AdminToken,
+		moderatorToken: cfg.ModeratorToken,
+	}
+}
+
+// Authenticate builds the viewer for a user handle and an optional role token.
+// A token that grants no role is rejected.
+func (a *Authenticator) Authenticate(user, token string) (Viewer, error) {
+	viewer := Viewer{User: strings.TrimSpace(user), Role: RoleUser}
+	switch {
+	case token == "":
+	case tokenMatches(token, a.adminToken):
+		viewer.Role = RoleAdmin
+	case tokenMatches(token, a.moderatorToken):
+		viewer.Role = RoleModerator
+	default:
+		return Viewer{}, ErrInvalidToken
+	}
+	return viewer, nil
+}
+
+// Middleware stores the viewer described by the X-User and Authorization headers in the request context.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer, err := a.Authenticate(r.Header.Get("X-User"), BearerToken(r.Header.Get("Authorization")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithViewer(r.Context(), viewer)))
+	})
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(header string) string {
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+// tokenMatches compares tokens in constant time. An unset expected token never matches.
+func tokenMatches(token, expected string) bool {
+	if expected == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
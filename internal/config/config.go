@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/likimiad/ozon_fintech/internal/logger"
 	"log/slog"
@@ -27,7 +30,161 @@ type RedisConfig struct {
 
 // ServerConfig represents the server configuration.
 type ServerConfig struct {
-	Port string `env:"HTTP_PORT"               env-default:"8080"`
+	Port         string        `env:"HTTP_PORT"      env-default:"8080"`
+	SSEKeepAlive time.Duration `env:"SSE_KEEP_ALIVE" env-default:"15s"`
+	SSERetry     time.Duration `env:"SSE_RETRY"      env-default:"3s"`
+
+	TrustedProxies []string `env:"TRUSTED_PROXIES" env-separator:"," env-default:""` // ? IPs or CIDRs whose X-Forwarded-For is honored
+}
+
+// validate checks the server transport settings that cannot be used as configured.
+func (c ServerConfig) validate() error {
+	var errs []error
+	if c.SSEKeepAlive <= 0 {
+		errs = append(errs, fmt.Errorf("SSE_KEEP_ALIVE must be positive, got %s", c.SSEKeepAlive))
+	}
+	if c.SSERetry <= 0 {
+		errs = append(errs, fmt.Errorf("SSE_RETRY must be positive, got %s", c.SSERetry))
+	}
+	return errors.Join(errs...)
+}
+
+// WebsocketConfig represents the websocket transport configuration.
+type WebsocketConfig struct {
+	AllowedOrigins   []string      `env:"WS_ALLOWED_ORIGINS"   env-separator:"," env-default:""` // ? Cross-site origins, the server's own is always allowed
+	MaxSubscriptions int           `env:"WS_MAX_SUBSCRIPTIONS" env-default:"20"`                 // ? Active subscriptions per connection
+	KeepAlive        time.Duration `env:"WS_KEEP_ALIVE"        env-default:"10s"`
+}
+
+// validate checks the websocket settings that cannot be used as configured.
+func (c WebsocketConfig) validate() error {
+	var errs []error
+	if c.MaxSubscriptions < 0 {
+		errs = append(errs, fmt.Errorf("WS_MAX_SUBSCRIPTIONS cannot be negative, got %d", c.MaxSubscriptions))
+	}
+	if c.KeepAlive <= 0 {
+		errs = append(errs, fmt.Errorf("WS_KEEP_ALIVE must be positive, got %s", c.KeepAlive))
+	}
+	return errors.Join(errs...)
+}
+
+// PersistedQueriesConfig represents the automatic persisted queries and allowlist configuration.
+type PersistedQueriesConfig struct {
+	APQTTL       time.Duration `env:"APQ_TTL"                    env-default:"24h"`
+	ManifestPath string        `env:"PERSISTED_QUERIES_MANIFEST" env-default:""`
+	Strict       bool          `env:"PERSISTED_QUERIES_STRICT"   env-default:"false"` // ? Only accept operations from the manifest
+}
+
+// AuthConfig represents the tokens granting elevated roles.
+type AuthConfig struct {
+	AdminToken     string `env:"AUTH_ADMIN_TOKEN"     env-default:""`
+	ModeratorToken string `env:"AUTH_MODERATOR_TOKEN" env-default:""`
+}
+
+// RateLimitConfig represents the mutation rate limits.
+type RateLimitConfig struct {
+	Backend string   `env:"RATE_LIMIT_BACKEND" env-default:"redis"` // ? redis, or memory for a single instance
+	Rules   []string `env:"RATE_LIMIT_RULES"   env-separator:"," env-default:"createComment:user=10/1m,createComment:ip=60/1m,createPost:user=5/1m,createPost:ip=30/1m,report:user=10/1h,report:ip=30/1h"`
+}
+
+// validate checks that the rate limiter backend is known.
+func (c RateLimitConfig) validate() error {
+	switch c.Backend {
+	case "redis", "memory":
+		return nil
+	default:
+		return fmt.Errorf("RATE_LIMIT_BACKEND must be redis or memory, got %q", c.Backend)
+	}
+}
+
+// ModerationConfig represents the content moderation filters.
+type ModerationConfig struct {
+	Filters          []string      `env:"MODERATION_FILTERS"            env-separator:"," env-default:"banned_words,links,repeated_chars,duplicates"`
+	BannedWords      []string      `env:"MODERATION_BANNED_WORDS"       env-separator:"," env-default:""`
+	MaxLinks         int           `env:"MODERATION_MAX_LINKS"          env-default:"3"`
+	MaxRepeatedChars int           `env:"MODERATION_MAX_REPEATED_CHARS" env-default:"10"`
+	DuplicateWindow  time.Duration `env:"MODERATION_DUPLICATE_WINDOW"   env-default:"10m"` // ? How far back the author's content is checked for duplicates
+}
+
+// WebhookConfig represents the webhook delivery worker.
+type WebhookConfig struct {
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"WEBHOOK_BATCH_SIZE"    env-default:"20"` // ? Deliveries sent concurrently per poll
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT"       env-default:"10s"`
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS"  env-default:"8"` // ? Failed attempts before a delivery is dead-lettered
+	BackoffBase  time.Duration `env:"WEBHOOK_BACKOFF_BASE"  env-default:"30s"`
+	BackoffMax   time.Duration `env:"WEBHOOK_BACKOFF_MAX"   env-default:"1h"`
+}
+
+// validate checks the webhook worker settings that cannot be used as configured.
+func (c WebhookConfig) validate() error {
+	var errs []error
+	if c.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_POLL_INTERVAL must be positive, got %s", c.PollInterval))
+	}
+	if c.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_BATCH_SIZE must be positive, got %d", c.BatchSize))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_TIMEOUT must be positive, got %s", c.Timeout))
+	}
+	if c.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be positive, got %d", c.MaxAttempts))
+	}
+	if c.BackoffBase <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_BACKOFF_BASE must be positive, got %s", c.BackoffBase))
+	}
+	if c.BackoffMax < c.BackoffBase {
+		errs = append(errs, fmt.Errorf("WEBHOOK_BACKOFF_MAX must be at least WEBHOOK_BACKOFF_BASE, got %s", c.BackoffMax))
+	}
+	return errors.Join(errs...)
+}
+
+// OutboxConfig represents the relay of the transactional outbox.
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE"    env-default:"100"`
+	BackoffMax   time.Duration `env:"OUTBOX_BACKOFF_MAX"   env-default:"5m"`  // ? Longest delay between retries of an event that failed to relay
+	Retention    time.Duration `env:"OUTBOX_RETENTION"     env-default:"24h"` // ? How long relayed events are kept
+}
+
+// validate checks the outbox relay settings that cannot be used as configured.
+func (c OutboxConfig) validate() error {
+	var errs []error
+	if c.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive, got %s", c.PollInterval))
+	}
+	if c.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("OUTBOX_BATCH_SIZE must be positive, got %d", c.BatchSize))
+	}
+	if c.BackoffMax < c.PollInterval {
+		errs = append(errs, fmt.Errorf("OUTBOX_BACKOFF_MAX must be at least OUTBOX_POLL_INTERVAL, got %s", c.BackoffMax))
+	}
+	if c.Retention <= 0 {
+		errs = append(errs, fmt.Errorf("OUTBOX_RETENTION must be positive, got %s", c.Retention))
+	}
+	return errors.Join(errs...)
+}
+
+// ServiceConfig represents the tunables of the post service.
+type ServiceConfig struct {
+	PostRetention   time.Duration `env:"POST_RETENTION" env-default:"720h"` // ? How long deleted posts can be restored
+	PurgeInterval   time.Duration `env:"PURGE_INTERVAL" env-default:"1h"`
+	EventLogSize    int64         `env:"EVENT_LOG_SIZE" env-default:"1000"` // ? Comment events kept per post for replay
+	IdempotencyTTL  time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"` // ? How long idempotency keys of create mutations are remembered
+	ReportThreshold int           `env:"REPORT_THRESHOLD" env-default:"5"`  // ? Open reports that send published content back to review, 0 disables
+}
+
+// validate checks the service tunables that cannot be used as configured.
+func (c ServiceConfig) validate() error {
+	var errs []error
+	if c.PurgeInterval <= 0 {
+		errs = append(errs, fmt.Errorf("PURGE_INTERVAL must be positive, got %s", c.PurgeInterval))
+	}
+	if c.PostRetention < 0 {
+		errs = append(errs, fmt.Errorf("POST_RETENTION cannot be negative, got %s", c.PostRetention))
+	}
+	return errors.Join(errs...)
 }
 
 // Config aggregates all configuration structures.
@@ -35,6 +192,26 @@ type Config struct {
 	DatabaseConfig
 	RedisConfig
 	ServerConfig
+	WebsocketConfig
+	PersistedQueriesConfig
+	AuthConfig
+	RateLimitConfig
+	ModerationConfig
+	WebhookConfig
+	OutboxConfig
+	ServiceConfig
+}
+
+// validate reports every configuration value the service cannot start with.
+func (c *Config) validate() error {
+	return errors.Join(
+		c.ServerConfig.validate(),
+		c.WebsocketConfig.validate(),
+		c.RateLimitConfig.validate(),
+		c.WebhookConfig.validate(),
+		c.OutboxConfig.validate(),
+		c.ServiceConfig.validate(),
+	)
 }
 
 // GetConfig loads and returns the application configuration.
@@ -65,6 +242,9 @@ func loadConfig() *Config {
 	if err != nil {
 		logger.FatalError("Error reading config file", err)
 	}
+	if err := cfg.validate(); err != nil {
+		logger.FatalError("Invalid configuration", err)
+	}
 
 	return &cfg
 }
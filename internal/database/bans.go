@@ -0,0 +1,152 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+var (
+	ErrAuthorBanned = errors.New("author is banned from commenting")
+	ErrThreadLocked = errors.New("comment thread is locked")
+	ErrBanExpired   = errors.New("ban expiry must be in the future")
+)
+
+// BanError is returned when a banned author tries to comment.
+type BanError struct {
+	Ban models.Ban
+}
+
+func (e *BanError) Error() string {
+	if e.Ban.PostID != nil {
+		return "author is banned from commenting on this post"
+	}
+	return ErrAuthorBanned.Error()
+}
+
+func (e *BanError) Unwrap() error {
+	return ErrAuthorBanned
+}
+
+// BanAuthor stops an author from commenting on one post, or everywhere when postID is nil.
+// A nil expiresAt makes the ban permanent until it is lifted.
+func (s *PostService) BanAuthor(author string, postID *uint, expiresAt *time.Time, reason *string, moderator string) (*models.Ban, error) {
+	if author == "" {
+		return nil, ErrEmptyAuthor
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrBanExpired
+	}
+	if postID != nil {
+		if err := s.DB.Select("id").First(&models.Post{}, *postID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	ban := &models.Ban{
+		Author:    author,
+		PostID:    postID,
+		Reason:    reason,
+		BannedBy:  moderator,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.DB.Create(ban).Error; err != nil {
+		slog.Error("error banning author", "author", author, "error", err)
+		return nil, err
+	}
+	slog.Info("author banned", "author", author, "post_id", postID, "expires_at", expiresAt, "moderator", moderator)
+	return ban, nil
+}
+
+// LiftBan removes a ban before it expires.
+func (s *PostService) LiftBan(id uint, moderator string) error {
+	result := s.DB.Delete(&models.Ban{}, id)
+	if result.Error != nil {
+		slog.Error("error lifting ban", "ban_id", id, "error", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	slog.Info("ban lifted", "ban_id", id, "moderator", moderator)
+	return nil
+}
+
+// GetBans returns the bans that have not expired, optionally only those of one author.
+func (s *PostService) GetBans(author *string) ([]models.Ban, error) {
+	query := s.DB.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if author != nil {
+		query = query.Where("author = ?", *author)
+	}
+
+	var bans []models.Ban
+	if err := query.Order("created_at DESC").Order("id DESC").Find(&bans).Error; err != nil {
+		slog.Error("error fetching bans", "error", err)
+		return nil, err
+	}
+	return bans, nil
+}
+
+// checkBan returns a BanError if the author may not comment on the post.
+func (s *PostService) checkBan(author string, postID uint) error {
+	var bans []models.Ban
+	err := s.DB.Where("author = ? AND (post_id IS NULL OR post_id = ?) AND (expires_at IS NULL OR expires_at > ?)",
+		author, postID, time.Now()).
+		Order("post_id NULLS FIRST").Limit(1).Find(&bans).Error
+	if err != nil {
+		return err
+	}
+	if len(bans) > 0 {
+		return &BanError{Ban: bans[0]}
+	}
+	return nil
+}
+
+// SetThreadLock locks or unlocks the thread below a comment.
+func (s *PostService) SetThreadLock(id uint, locked bool, moderator string) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.DB.First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&comment).Update("locked", locked).Error; err != nil {
+			return err
+		}
+		comment.Locked = locked
+		var err error
+		event, err = addOutbox(tx, models.OutboxThreadLockChanged, comment.PostID, outboxPayload{Comment: &comment})
+		return err
+	})
+	if err != nil {
+		slog.Error("error locking thread", "comment_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("thread lock changed", "comment_id", id, "locked", locked, "moderator", moderator)
+	s.relayOutbox(event)
+	return &comment, nil
+}
+
+// checkThreadLock returns ErrThreadLocked if the comment or any of its ancestors is locked.
+func (s *PostService) checkThreadLock(commentID uint) error {
+	var locked bool
+	err := s.DB.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, comment_id, locked FROM comments WHERE id = ?
+			UNION ALL
+			SELECT c.id, c.comment_id, c.locked FROM comments c JOIN ancestors a ON c.id = a.comment_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE locked)`, commentID).Scan(&locked).Error
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrThreadLocked
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+)
+
+var ErrConflict = errors.New("version conflict")
+
+// ConflictError is returned when an update was based on an outdated version of a post or comment.
+type ConflictError struct {
+	CurrentVersion int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("version conflict: current version is %d", e.CurrentVersion)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// postConflict reports the version of a post that changed under a conditional update.
+func (s *PostService) postConflict(tx *gorm.DB, id uint) error {
+	var current models.Post
+	if err := tx.Select("version").First(&current, id).Error; err != nil {
+		return err
+	}
+	return &ConflictError{CurrentVersion: current.Version}
+}
+
+// commentConflict reports the version of a comment that changed under a conditional update.
+func (s *PostService) commentConflict(tx *gorm.DB, id uint) error {
+	var current models.Comment
+	if err := tx.Select("version", "is_deleted").First(&current, id).Error; err != nil {
+		return err
+	}
+	if current.IsDeleted {
+		return ErrCommentDeleted
+	}
+	return &ConflictError{CurrentVersion: current.Version}
+}
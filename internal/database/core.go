@@ -8,31 +8,52 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/config"
 	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/moderation"
+	"github.com/likimiad/ozon_fintech/internal/pubsub"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log/slog"
 )
 
 const (
 	CacheTTL        = time.Hour // ? Cache Time-to-Live
 	MemoryThreshold = 0.75      // ? Memory usage threshold for Redis
+
+	SubscriptionBuffer = 16 // ? Events buffered per subscriber before dropping
 )
 
 var (
-	ErrPostDisabled = errors.New("comments are disabled for this post")
-	ErrNotFound     = errors.New("record not found")
+	ErrPostDisabled   = errors.New("comments are disabled for this post")
+	ErrCommentDeleted = errors.New("comment has been deleted")
+	ErrNotFound       = errors.New("record not found")
 )
 
 type PostService struct {
-	DB *Database
-	RC *redis.Client
+	DB  *Database
+	RC  *redis.Client
+	cfg config.ServiceConfig
+
+	moderator *moderation.Pipeline // ? Set up by GetDB, moderation is skipped when nil
+
+	CommentEvents    *pubsub.Broker[models.CommentEvent]     // ? Topics are post IDs
+	PostEvents       *pubsub.Broker[models.PostEvent]        // ? Topics are post IDs and AllPostsTopic
+	ReactionsChanged *pubsub.Broker[*models.ReactionSummary] // ? Topics are post IDs
+	Notifications    *pubsub.Broker[*models.Notification]    // ? Topics are recipient handles
 }
 
 // NewPostService creates a new PostService instance.
-func NewPostService(db *Database, rc *redis.Client) *PostService {
+func NewPostService(db *Database, rc *redis.Client, cfg config.ServiceConfig) *PostService {
 	return &PostService{
-		DB: db,
-		RC: rc,
+		DB:               db,
+		RC:               rc,
+		cfg:              cfg,
+		CommentEvents:    pubsub.NewBroker[models.CommentEvent](SubscriptionBuffer),
+		PostEvents:       pubsub.NewBroker[models.PostEvent](SubscriptionBuffer),
+		ReactionsChanged: pubsub.NewBroker[*models.ReactionSummary](SubscriptionBuffer),
+		Notifications:    pubsub.NewBroker[*models.Notification](SubscriptionBuffer),
 	}
 }
 
@@ -41,6 +62,16 @@ func (s *PostService) CreatePost(post *models.Post) error {
 	if err := s.validatePost(post); err != nil {
 		return err
 	}
+	decision, err := s.moderate(moderation.Content{
+		Kind:    moderation.KindPost,
+		Author:  post.Author,
+		Title:   post.Title,
+		Content: post.Content,
+	})
+	if err != nil {
+		return err
+	}
+	post.Status = statusFor(decision)
 
 	slog.Info("creating new post", "title", post.Title, "author", post.Author)
 
@@ -48,78 +79,188 @@ func (s *PostService) CreatePost(post *models.Post) error {
 		post.CreatedAt = time.Now()
 	}
 
-	err := s.DB.Create(post).Error
-	if err != nil {
-		slog.Error("error creating post", "title", post.Title, "error", err)
+	if err := s.ensureUser(post.Author); err != nil {
 		return err
 	}
 
-	// Clear and update post cache
-	s.clearCache("posts")
-	var posts []models.Post
-	if err := s.DB.Preload("Comments.Replies").Find(&posts).Error; err != nil {
-		slog.Error("error fetching posts from database to update cache", "error", err)
+	var event *models.OutboxEvent
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if err := syncMentions(tx, models.TargetPost, post.ID, post.Author, post.Title, post.Content); err != nil {
+			return err
+		}
+		event, err = addOutbox(tx, models.OutboxPostChanged, post.ID, outboxPayload{Post: post})
+		return err
+	})
+	if err != nil {
+		slog.Error("error creating post", "title", post.Title, "error", err)
 		return err
 	}
-	s.setToCache("posts", posts)
+
+	s.relayOutbox(event)
 
 	return nil
 }
 
 // UpdatePost modifies an existing post and updates the cache.
-func (s *PostService) UpdatePost(post *models.Post) error {
+// When the title or content changes, the previous version is stored as a revision.
+// The write only succeeds if nobody updated the post since expectedVersion, or since
+// it was read when expectedVersion is nil; otherwise a ConflictError is returned.
+// Only the author and moderators may update a post.
+func (s *PostService) UpdatePost(post *models.Post, viewer auth.Viewer, expectedVersion *int) error {
 	if err := s.validatePost(post); err != nil {
 		return err
 	}
-
-	err := s.DB.Save(post).Error
+	decision, err := s.moderate(moderation.Content{
+		Kind:    moderation.KindPost,
+		ID:      post.ID,
+		Author:  post.Author,
+		Title:   post.Title,
+		Content: post.Content,
+	})
 	if err != nil {
-		slog.Error("error updating post", "title", post.Title, "error", err)
 		return err
 	}
 
-	// Очистка и обновление кэша постов
-	s.clearCache("posts")
-	var posts []models.Post
-	if err := s.DB.Preload("Comments.Replies").Find(&posts).Error; err != nil {
-		slog.Error("error fetching posts from database to update cache", "error", err)
+	var events []*models.OutboxEvent
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Post
+		if err := tx.First(&current, post.ID).Error; err != nil {
+			return err
+		}
+		if !CanEdit(viewer, current.Author) {
+			return auth.ErrForbidden
+		}
+		// ? Without an expected version the edit is based on the version the caller read
+		base := post.Version
+		if expectedVersion != nil {
+			base = *expectedVersion
+		}
+		if base != current.Version {
+			return &ConflictError{CurrentVersion: current.Version}
+		}
+		post.Status = editedStatus(current.Status, decision)
+		if current.Title != post.Title || current.Content != post.Content {
+			revision := &models.Revision{
+				TargetType: models.TargetPost,
+				TargetID:   current.ID,
+				Title:      &current.Title,
+				Content:    current.Content,
+				EditedBy:   viewer.User,
+			}
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+			post.Edited = true
+		}
+		post.Version = current.Version + 1
+		post.UpdatedAt = time.Now()
+		// ? Only editable columns are written, Score is maintained by reactions and may be stale on a cached post
+		result := tx.Model(&models.Post{}).Where("id = ? AND version = ?", post.ID, base).Updates(map[string]interface{}{
+			"title":            post.Title,
+			"content":          post.Content,
+			"comments_enabled": post.CommentsEnabled,
+			"edited":           post.Edited,
+			"status":           post.Status,
+			"version":          post.Version,
+			"updated_at":       post.UpdatedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return s.postConflict(tx, post.ID)
+		}
+		if err := syncMentions(tx, models.TargetPost, post.ID, current.Author, post.Title, post.Content); err != nil {
+			return err
+		}
+
+		changed, err := addOutbox(tx, models.OutboxPostChanged, post.ID, outboxPayload{Post: post, Previous: current.Status})
+		if err != nil {
+			return err
+		}
+		events = append(events, changed)
+		if current.CommentsEnabled != post.CommentsEnabled {
+			toggled, err := addOutbox(tx, models.OutboxCommentsToggled, post.ID, outboxPayload{Post: post})
+			if err != nil {
+				return err
+			}
+			events = append(events, toggled)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("error updating post", "title", post.Title, "error", err)
 		return err
 	}
-	s.setToCache("posts", posts)
 
-	// Очистка и обновление кэша конкретного поста
-	s.clearCache(fmt.Sprintf("post:%d", post.ID))
-	s.setToCache(fmt.Sprintf("post:%d", post.ID), post)
+	s.relayOutbox(events...)
 
 	return nil
 }
 
-// DeletePost removes a post and its comments from the database and cache.
-func (s *PostService) DeletePost(id uint) error {
+// DeletePost soft-deletes a post. The post and its comments stay in the database
+// until the retention window passes and the purge job removes them.
+// Only the author and admins may delete a post.
+func (s *PostService) DeletePost(id uint, viewer auth.Viewer) error {
+	var post models.Post
+	var event *models.OutboxEvent
 	if err := s.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("post_id = ?", id).Delete(&models.Comment{}).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, id).Error; err != nil {
+			return err
+		}
+		if !(viewer.User != "" && viewer.User == post.Author) && viewer.Role < auth.RoleAdmin {
+			return auth.ErrForbidden
+		}
+
+		// ? Admins authenticated without a handle leave no deleter behind
+		var deletedBy *string
+		if viewer.User != "" {
+			deletedBy = &viewer.User
+		}
+		if err := tx.Model(&models.Post{}).Where("id = ?", id).Update("deleted_by", deletedBy).Error; err != nil {
 			return err
 		}
 		if err := tx.Delete(&models.Post{}, id).Error; err != nil {
 			return err
 		}
-		return nil
+		if err := tx.Unscoped().First(&post, id).Error; err != nil {
+			return err
+		}
+		var err error
+		event, err = addOutbox(tx, models.OutboxPostDeleted, id, outboxPayload{Post: &post})
+		return err
 	}); err != nil {
 		return err
 	}
 
-	// Очистка кэша
-	s.clearCache("posts")
-	s.clearCache(fmt.Sprintf("comments:%d", id))
-	s.clearCache(fmt.Sprintf("post:%d", id))
+	s.relayOutbox(event)
 
 	return nil
 }
 
-// GetPosts retrieves all posts, using cache if available.
-func (s *PostService) GetPosts() ([]models.Post, error) {
+// GetPosts retrieves the posts matching the query that the viewer may see, using cache if available.
+func (s *PostService) GetPosts(query PostQuery, viewer auth.Viewer) ([]models.Post, error) {
+	posts, err := s.loadPosts(query)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.Post, 0, len(posts))
+	for _, post := range posts {
+		if CanView(viewer, post.Author, post.Status) {
+			visible = append(visible, post)
+		}
+	}
+	return visible, nil
+}
+
+// loadPosts retrieves every post matching the query, using cache if available.
+func (s *PostService) loadPosts(query PostQuery) ([]models.Post, error) {
 	var posts []models.Post
-	cacheKey := "posts"
+	cacheKey := query.cacheKey()
 
 	if err := s.getFromCache(cacheKey, &posts); err == nil {
 		slog.Info("cache hit for posts")
@@ -137,8 +278,12 @@ func (s *PostService) GetPosts() ([]models.Post, error) {
 		return posts, nil
 	}
 
-	slog.Info("cache miss for posts, querying database")
-	result := s.DB.Preload("Comments.Replies").Find(&posts)
+	slog.Info("cache miss for posts, querying database", "key", cacheKey)
+	db := s.DB.DB
+	if query.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	result := db.Preload("Comments.Replies").Scopes(query.apply).Find(&posts)
 	if result.Error != nil {
 		slog.Error("error fetching posts from database", "error", result.Error)
 		return nil, result.Error
@@ -201,7 +346,8 @@ func (s *PostService) GetPostByID(id uint) (*models.Post, error) {
 }
 
 // CreateComment adds a new comment to a post and updates the cache.
-func (s *PostService) CreateComment(postID uint, commentID *uint, author, content string) (*models.Comment, error) {
+// The author must be the viewer, so bans cannot be dodged by naming another author.
+func (s *PostService) CreateComment(postID uint, commentID *uint, author, content string, viewer auth.Viewer) (*models.Comment, error) {
 	comment := &models.Comment{
 		PostID:    postID,
 		CommentID: commentID,
@@ -212,6 +358,18 @@ func (s *PostService) CreateComment(postID uint, commentID *uint, author, conten
 	if err := s.validateComment(comment); err != nil {
 		return nil, err
 	}
+	if viewer.User == "" || viewer.User != comment.Author {
+		return nil, &FieldError{Field: "author", Err: ErrAuthorNotViewer}
+	}
+	decision, err := s.moderate(moderation.Content{
+		Kind:    moderation.KindComment,
+		Author:  comment.Author,
+		Content: comment.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	comment.Status = statusFor(decision)
 
 	var post models.Post
 	if err := s.DB.First(&post, comment.PostID).Error; err != nil {
@@ -224,12 +382,21 @@ func (s *PostService) CreateComment(postID uint, commentID *uint, author, conten
 		return nil, ErrPostDisabled
 	}
 
+	if err := s.checkBan(viewer.User, comment.PostID); err != nil {
+		slog.Warn("attempt to comment while banned", "author", viewer.User, "post_id", comment.PostID, "error", err)
+		return nil, err
+	}
+
 	if comment.CommentID != nil {
 		var parentComment models.Comment
 		if err := s.DB.First(&parentComment, *comment.CommentID).Error; err != nil {
 			slog.Error("error fetching parent comment", "comment_id", *comment.CommentID, "error", err)
 			return nil, err
 		}
+		if err := s.checkThreadLock(parentComment.ID); err != nil {
+			slog.Warn("attempt to reply in locked thread", "comment_id", parentComment.ID, "error", err)
+			return nil, err
+		}
 	}
 
 	slog.Info("creating new comment", "author", comment.Author, "post_id", comment.PostID)
@@ -237,77 +404,284 @@ func (s *PostService) CreateComment(postID uint, commentID *uint, author, conten
 	if comment.CreatedAt.IsZero() {
 		comment.CreatedAt = time.Now()
 	}
+	comment.LastActivityAt = comment.CreatedAt
 
-	err := s.DB.Create(comment).Error
-	if err != nil {
-		slog.Error("error creating comment", "author", comment.Author, "error", err)
+	if err := s.ensureUser(comment.Author); err != nil {
 		return nil, err
 	}
 
-	s.clearCache(fmt.Sprintf("comments:%d", comment.PostID))
-	var comments []models.Comment
-	if err := s.DB.Where("post_id = ?", comment.PostID).Preload("Replies").Find(&comments).Error; err != nil {
-		slog.Error("error fetching comments from database to update cache", "post_id", comment.PostID, "error", err)
+	var event *models.OutboxEvent
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if err := syncMentions(tx, models.TargetComment, comment.ID, comment.Author, comment.Content); err != nil {
+			return err
+		}
+		var err error
+		if event, err = addOutbox(tx, models.OutboxCommentChanged, comment.PostID, outboxPayload{Comment: comment}); err != nil {
+			return err
+		}
+		if comment.CommentID == nil {
+			return nil
+		}
+		// ? Bubble the reply time up to every ancestor for ACTIVE ordering
+		return tx.Exec(`
+			WITH RECURSIVE ancestors AS (
+				SELECT id, comment_id FROM comments WHERE id = ?
+				UNION ALL
+				SELECT c.id, c.comment_id FROM comments c JOIN ancestors a ON c.id = a.comment_id
+			)
+			UPDATE comments SET last_activity_at = ?
+			WHERE id IN (SELECT id FROM ancestors) AND last_activity_at < ?`,
+			*comment.CommentID, comment.LastActivityAt, comment.LastActivityAt).Error
+	})
+	if err != nil {
+		slog.Error("error creating comment", "author", comment.Author, "error", err)
 		return nil, err
 	}
-	s.setToCache(fmt.Sprintf("comments:%d", comment.PostID), comments)
+
+	s.relayOutbox(event)
 
 	return comment, nil
 }
 
 // UpdateComment modifies an existing comment and updates the cache.
-func (s *PostService) UpdateComment(id uint, content string) (*models.Comment, error) {
+// The previous content is stored as a revision, an unchanged content is not written at all. Like UpdatePost, the write is
+// conditional on the comment version and stale writes return a ConflictError.
+// Only the author and moderators may update a comment.
+func (s *PostService) UpdateComment(id uint, content string, viewer auth.Viewer, expectedVersion *int) (*models.Comment, error) {
 	var comment models.Comment
 	if err := s.DB.First(&comment, id).Error; err != nil {
 		return nil, err
 	}
+	if comment.IsDeleted {
+		return nil, ErrCommentDeleted
+	}
+	if !CanEdit(viewer, comment.Author) {
+		return nil, auth.ErrForbidden
+	}
+	if expectedVersion != nil && *expectedVersion != comment.Version {
+		return nil, &ConflictError{CurrentVersion: comment.Version}
+	}
+	// ? Nothing to store, the comment is neither marked edited nor given a new version
+	if content == comment.Content {
+		return &comment, nil
+	}
 
+	revision := &models.Revision{
+		TargetType: models.TargetComment,
+		TargetID:   comment.ID,
+		Content:    comment.Content,
+		EditedBy:   viewer.User,
+	}
+
+	readVersion := comment.Version
 	comment.Content = content
+	comment.Edited = true
+	comment.Version++
 	comment.UpdatedAt = time.Now()
 
 	if err := s.validateComment(&comment); err != nil {
 		return nil, err
 	}
-
-	if err := s.DB.Save(&comment).Error; err != nil {
+	decision, err := s.moderate(moderation.Content{
+		Kind:    moderation.KindComment,
+		ID:      comment.ID,
+		Author:  comment.Author,
+		Content: comment.Content,
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	s.clearCache(fmt.Sprintf("comments:%d", comment.PostID))
-	var comments []models.Comment
-	if err := s.DB.Where("post_id = ?", comment.PostID).Preload("Replies").Find(&comments).Error; err != nil {
-		slog.Error("error fetching comments from database to update cache", "post_id", comment.PostID, "error", err)
+	previous := comment.Status
+	comment.Status = editedStatus(previous, decision)
+
+	var event *models.OutboxEvent
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Comment{}).Where("id = ? AND version = ? AND NOT is_deleted", comment.ID, readVersion).Updates(map[string]interface{}{
+			"content":    comment.Content,
+			"edited":     comment.Edited,
+			"status":     comment.Status,
+			"version":    comment.Version,
+			"updated_at": comment.UpdatedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return s.commentConflict(tx, comment.ID)
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := syncMentions(tx, models.TargetComment, comment.ID, comment.Author, comment.Content); err != nil {
+			return err
+		}
+		var err error
+		event, err = addOutbox(tx, models.OutboxCommentChanged, comment.PostID, outboxPayload{Comment: &comment, Previous: previous})
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
-	s.setToCache(fmt.Sprintf("comments:%d", comment.PostID), comments)
+
+	s.relayOutbox(event)
 
 	return &comment, nil
 }
 
 // DeleteComment logically deletes a comment and updates the cache.
-func (s *PostService) DeleteComment(id uint) error {
-	var comment models.Comment
-	if err := s.DB.First(&comment, id).Error; err != nil {
+// The content is kept for moderation but no longer exposed; the comment stays
+// in the tree as a tombstone while it has replies. Only the author or a moderator
+// may delete it.
+func (s *PostService) DeleteComment(id uint, viewer auth.Viewer) error {
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// ? Lock the comment so an edit or status change cannot land between the read and the write
+		var comment models.Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&comment, id).Error; err != nil {
+			return err
+		}
+		if comment.IsDeleted {
+			return ErrCommentDeleted
+		}
+
+		var deletedBy models.DeletionActor
+		switch {
+		case viewer.User != "" && viewer.User == comment.Author:
+			deletedBy = models.DeletedByAuthor
+		case viewer.Role >= auth.RoleModerator:
+			deletedBy = models.DeletedByModerator
+		default:
+			return auth.ErrForbidden
+		}
+		now := time.Now()
+
+		comment.IsDeleted = true
+		comment.DeletedAt = &now
+		comment.DeletedBy = &deletedBy
+		comment.Version++
+		comment.UpdatedAt = now
+
+		err := tx.Model(&models.Comment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+			"is_deleted": comment.IsDeleted,
+			"deleted_at": comment.DeletedAt,
+			"deleted_by": comment.DeletedBy,
+			"version":    comment.Version,
+			"updated_at": comment.UpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		event, err = addOutbox(tx, models.OutboxCommentDeleted, comment.PostID, outboxPayload{Comment: &comment})
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	comment.IsDeleted = true
-	comment.Content = "Comment deleted by user"
-	comment.UpdatedAt = time.Now()
+	s.relayOutbox(event)
 
-	if err := s.DB.Save(&comment).Error; err != nil {
-		return err
+	return nil
+}
+
+// CommentTree holds the comments of a post that one viewer may see, grouped by parent,
+// so the comments and replies of a request are served from a single load.
+type CommentTree struct {
+	all     []models.Comment
+	replies map[uint][]models.Comment
+}
+
+// GetCommentTree loads the comments of a post that the viewer may see. Comments the
+// viewer may not see are left out together with their replies.
+func (s *PostService) GetCommentTree(postID uint, viewer auth.Viewer) (*CommentTree, error) {
+	comments, err := s.loadComments(postID)
+	if err != nil {
+		return nil, err
+	}
+	tree := &CommentTree{
+		all:     pruneTombstones(visibleComments(comments, viewer)),
+		replies: make(map[uint][]models.Comment),
 	}
+	for _, comment := range tree.all {
+		if comment.CommentID != nil {
+			tree.replies[*comment.CommentID] = append(tree.replies[*comment.CommentID], comment)
+		}
+	}
+	return tree, nil
+}
 
-	s.clearCache(fmt.Sprintf("comments:%d", comment.PostID))
+// Page returns one page of the comments of the tree in the requested order, or of
+// the direct replies to parentID when it is set. The tree itself is left untouched.
+func (t *CommentTree) Page(parentID *uint, page CommentPage) ([]models.Comment, error) {
+	comments := t.all
+	if parentID != nil {
+		comments = t.replies[*parentID]
+	}
+	return page.apply(append(make([]models.Comment, 0, len(comments)), comments...))
+}
+
+// pruneTombstones drops deleted comments that have no remaining replies.
+// A deleted comment whose replies were all pruned is pruned as well.
+func pruneTombstones(comments []models.Comment) []models.Comment {
+	children := make(map[uint][]int, len(comments))
+	for i := range comments {
+		if comments[i].CommentID != nil {
+			children[*comments[i].CommentID] = append(children[*comments[i].CommentID], i)
+		}
+	}
+
+	visible := make(map[uint]bool, len(comments))
+	var isVisible func(i int) bool
+	isVisible = func(i int) bool {
+		id := comments[i].ID
+		if v, ok := visible[id]; ok {
+			return v
+		}
+		v := !comments[i].IsDeleted
+		for _, child := range children[id] {
+			// ? Every child is evaluated so the memo covers the whole subtree
+			if isVisible(child) {
+				v = true
+			}
+		}
+		visible[id] = v
+		return v
+	}
+
+	kept := make([]models.Comment, 0, len(comments))
+	for i := range comments {
+		if isVisible(i) {
+			kept = append(kept, comments[i])
+		}
+	}
+	return kept
+}
+
+// loadComments returns every comment of a post, using cache if available.
+func (s *PostService) loadComments(postID uint) ([]models.Comment, error) {
 	var comments []models.Comment
-	if err := s.DB.Where("post_id = ?", comment.PostID).Preload("Replies").Find(&comments).Error; err != nil {
-		slog.Error("error fetching comments from database to update cache", "post_id", comment.PostID, "error", err)
-		return err
+	if err := s.getFromCache(fmt.Sprintf("comments:%d", postID), &comments); err == nil {
+		slog.Info("cache hit for comments", "post_id", postID)
+		return comments, nil
 	}
-	s.setToCache(fmt.Sprintf("comments:%d", comment.PostID), comments)
 
-	return nil
+	slog.Info("cache miss for comments", "post_id", postID, "operation", "querying database")
+	return s.refreshCommentsCache(postID)
+}
+
+// refreshCommentsCache reloads the comments of a post from the database into the cache.
+func (s *PostService) refreshCommentsCache(postID uint) ([]models.Comment, error) {
+	key := fmt.Sprintf("comments:%d", postID)
+	s.clearCache(key)
+	var comments []models.Comment
+	if err := s.DB.Where("post_id = ?", postID).Preload("Replies").Find(&comments).Error; err != nil {
+		slog.Error("error fetching comments from database to update cache", "post_id", postID, "error", err)
+		return nil, err
+	}
+	s.setToCache(key, comments)
+	return comments, nil
 }
 
 // getFromCache retrieves data from Redis cache.
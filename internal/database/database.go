@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/likimiad/ozon_fintech/internal/config"
-	"github.com/likimiad/ozon_fintech/internal/database/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -51,11 +50,14 @@ func GetDB(cfg config.Config) (*PostService, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.Post{}, &models.Comment{}); err != nil {
-		return nil, ErrDatabaseMigration
+	if err := migrate(db); err != nil {
+		return nil, err
 	}
 
-	postService := NewPostService(db, rc)
+	postService := NewPostService(db, rc, cfg.ServiceConfig)
+	if postService.moderator, err = newModerator(postService, cfg.ModerationConfig); err != nil {
+		return nil, err
+	}
 
 	return postService, nil
 }
@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"log/slog"
+)
+
+var (
+	ErrUnknownEvent  = errors.New("unknown event type in event log")
+	ErrEventsExpired = errors.New("missed comment events are no longer available, refetch the comments and subscribe without sinceSequence")
+)
+
+// Comment event types as stored in the event log.
+const (
+	eventCommentAdded   = "comment_added"
+	eventCommentUpdated = "comment_updated"
+	eventCommentDeleted = "comment_deleted"
+)
+
+// ? Keeps the sequence of an event relayed more than once, see commentSequence
+const sequenceKeyTTL = 24 * time.Hour
+
+// commentSequenceScript returns the sequence already assigned to the event or assigns the next one of the post.
+var commentSequenceScript = redis.NewScript(`
+local assigned = redis.call('GET', KEYS[2])
+if assigned then
+	return tonumber(assigned)
+end
+local sequence = redis.call('INCR', KEYS[1])
+redis.call('SET', KEYS[2], sequence, 'PX', ARGV[1])
+return sequence
+`)
+
+// publishCommentEvent assigns the next sequence number of the post to the event
+// built by newEvent, appends it to the post's event log and delivers it to subscribers
+// and webhooks. An event relayed again under the same eventID keeps its sequence, so
+// readers of the log and subscribers resuming with sinceSequence drop the duplicate.
+func (s *PostService) publishCommentEvent(eventID string, postID uint, newEvent func(sequence int64) models.CommentEvent) error {
+	sequence, err := commentSequenceScript.Run(context.Background(), s.RC,
+		[]string{fmt.Sprintf("comment_events:seq:%d", postID), fmt.Sprintf("comment_events:assigned:%s", eventID)},
+		sequenceKeyTTL.Milliseconds()).Int64()
+	if err != nil {
+		slog.Warn("failed to assign comment event sequence", "post_id", postID, "error", err)
+		return err
+	}
+	event := newEvent(sequence)
+
+	if err := s.appendCommentEvent(postID, event); err != nil {
+		return err
+	}
+	s.CommentEvents.Publish(strconv.FormatUint(uint64(postID), 10), event)
+	return s.enqueueCommentWebhook(eventID, event)
+}
+
+// appendCommentEvent stores the event in the bounded per-post Redis Stream used for replay.
+// Only the sequence and comment ID are kept, replay reads the comment as it is by then.
+func (s *PostService) appendCommentEvent(postID uint, event models.CommentEvent) error {
+	var eventType string
+	var comment *models.Comment
+	switch e := event.(type) {
+	case *models.CommentAdded:
+		eventType, comment = eventCommentAdded, e.Comment
+	case *models.CommentUpdated:
+		eventType, comment = eventCommentUpdated, e.Comment
+	case *models.CommentDeleted:
+		eventType, comment = eventCommentDeleted, e.Comment
+	}
+
+	err := s.RC.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: fmt.Sprintf("comment_events:log:%d", postID),
+		MaxLen: s.cfg.EventLogSize,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":       eventType,
+			"sequence":   event.GetSequence(),
+			"comment_id": comment.ID,
+		},
+	}).Err()
+	if err != nil {
+		slog.Warn("failed to append comment event to log", "post_id", postID, "error", err)
+	}
+	return err
+}
+
+// SubscribeCommentEvents streams comment events of a post until ctx is done.
+// When since is set, logged events with a greater sequence are replayed first,
+// so a client reconnecting with its last seen sequence misses nothing that is
+// still in the log.
+func (s *PostService) SubscribeCommentEvents(ctx context.Context, postID uint, since *int64) (<-chan models.CommentEvent, error) {
+	// ? Subscribe before reading the log so nothing published in between is lost
+	live := s.CommentEvents.Subscribe(ctx, strconv.FormatUint(uint64(postID), 10))
+	if since == nil {
+		return live, nil
+	}
+
+	missed, err := s.commentEventsSince(postID, *since)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan models.CommentEvent, SubscriptionBuffer)
+	go func() {
+		defer close(events)
+		last := *since
+		for _, event := range missed {
+			select {
+			case events <- event:
+				last = event.GetSequence()
+			case <-ctx.Done():
+				return
+			}
+		}
+		for event := range live {
+			if event.GetSequence() <= last {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// loggedEvent is an entry of the comment event log.
+type loggedEvent struct {
+	eventType string
+	sequence  int64
+	commentID uint
+}
+
+// commentEventsSince replays logged events of a post with a sequence greater than since, in order.
+// Comments are read from the database, so content deleted or hidden since is not replayed.
+// ErrEventsExpired is returned when some of those events were already trimmed from the log.
+func (s *PostService) commentEventsSince(postID uint, since int64) ([]models.CommentEvent, error) {
+	ctx := context.Background()
+	messages, err := s.RC.XRange(ctx, fmt.Sprintf("comment_events:log:%d", postID), "-", "+").Result()
+	if err != nil {
+		slog.Error("error reading comment event log", "post_id", postID, "error", err)
+		return nil, err
+	}
+
+	entries := make([]loggedEvent, 0, len(messages))
+	for _, message := range messages {
+		entry, err := decodeLoggedEvent(message.Values)
+		if err != nil {
+			slog.Warn("skipping malformed comment event", "post_id", postID, "id", message.ID, "error", err)
+			continue
+		}
+		if entry.sequence > since {
+			entries = append(entries, entry)
+		}
+	}
+
+	// ? Concurrent publishers may append slightly out of order
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].sequence < entries[j].sequence
+	})
+
+	latest, err := s.RC.Get(ctx, fmt.Sprintf("comment_events:seq:%d", postID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		slog.Error("error reading comment event sequence", "post_id", postID, "error", err)
+		return nil, err
+	}
+	if since < latest && (len(entries) == 0 || entries[0].sequence > since+1) {
+		slog.Warn("comment events missed by the client are no longer in the log", "post_id", postID, "since", since)
+		return nil, ErrEventsExpired
+	}
+
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.commentID
+	}
+	var comments []models.Comment
+	if err := s.DB.Where("id IN ?", ids).Find(&comments).Error; err != nil {
+		slog.Error("error fetching replayed comments", "post_id", postID, "error", err)
+		return nil, err
+	}
+	current := make(map[uint]*models.Comment, len(comments))
+	for i := range comments {
+		current[comments[i].ID] = &comments[i]
+	}
+
+	events := make([]models.CommentEvent, 0, len(entries))
+	for i, entry := range entries {
+		// ? Events relayed again by the outbox are logged twice with the same sequence
+		if i > 0 && entries[i-1].sequence == entry.sequence {
+			continue
+		}
+		if event := replayedEvent(entry, current[entry.commentID]); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// replayedEvent rebuilds a logged event around the current state of its comment.
+// Additions and edits of comments that are no longer published are left out, and
+// deletions carry a tombstone so neither leaks the author or content.
+func replayedEvent(entry loggedEvent, comment *models.Comment) models.CommentEvent {
+	if comment == nil {
+		// ! Purged together with its post
+		return nil
+	}
+	visible := comment.Status == models.StatusPublished && !comment.IsDeleted
+
+	switch entry.eventType {
+	case eventCommentAdded:
+		if visible {
+			return &models.CommentAdded{Sequence: entry.sequence, Comment: comment}
+		}
+	case eventCommentUpdated:
+		if visible {
+			return &models.CommentUpdated{Sequence: entry.sequence, Comment: comment}
+		}
+	case eventCommentDeleted:
+		if visible {
+			// ! Published again after the deletion was logged, a later event carries it
+			return nil
+		}
+		tombstone := *comment
+		tombstone.IsDeleted = true
+		tombstone.Content = ""
+		return &models.CommentDeleted{Sequence: entry.sequence, Comment: &tombstone}
+	}
+	return nil
+}
+
+// decodeLoggedEvent reads an entry of the comment event log.
+func decodeLoggedEvent(values map[string]interface{}) (loggedEvent, error) {
+	entry := loggedEvent{}
+	entry.eventType, _ = values["type"].(string)
+	switch entry.eventType {
+	case eventCommentAdded, eventCommentUpdated, eventCommentDeleted:
+	default:
+		return entry, ErrUnknownEvent
+	}
+
+	sequence, _ := values["sequence"].(string)
+	commentID, _ := values["comment_id"].(string)
+	var err error
+	if entry.sequence, err = strconv.ParseInt(sequence, 10, 64); err != nil {
+		return entry, err
+	}
+	id, err := strconv.ParseUint(commentID, 10, 64)
+	if err != nil {
+		return entry, err
+	}
+	entry.commentID = uint(id)
+	return entry, nil
+}
+
+// AllPostsTopic receives every post event in addition to the post's own topic.
+const AllPostsTopic = "*"
+
+// publishPostEvent delivers a post event to subscribers of the post and of all posts,
+// and to the webhooks subscribed to it.
+func (s *PostService) publishPostEvent(eventID string, postID uint, event models.PostEvent) error {
+	s.PostEvents.Publish(strconv.FormatUint(uint64(postID), 10), event)
+	s.PostEvents.Publish(AllPostsTopic, event)
+	return s.enqueuePostWebhook(eventID, event)
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"log/slog"
+)
+
+var ErrRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+
+const (
+	// idempotencyPending marks a key whose request has not finished yet.
+	idempotencyPending = "pending"
+	// ? Long enough for a create to finish, short enough that a crashed request does not block retries for long
+	idempotencyPendingTTL = 30 * time.Second
+	// ? Attempts at storing the created ID before the request fails
+	idempotencyStoreAttempts = 3
+)
+
+// CreatePostOnce creates the post unless the author already created one with the same
+// idempotency key within IdempotencyTTL, in which case the original post is returned.
+func (s *PostService) CreatePostOnce(post *models.Post, key string) (*models.Post, error) {
+	id, replayed, err := s.once(fmt.Sprintf("idempotency:post:%s:%s", post.Author, key), func() (uint, error) {
+		if err := s.CreatePost(post); err != nil {
+			return 0, err
+		}
+		return post.ID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		slog.Info("replaying idempotent post creation", "post_id", id, "author", post.Author)
+		return s.GetPostByID(id)
+	}
+	return post, nil
+}
+
+// CreateCommentOnce creates the comment unless the author already created one on the post
+// with the same idempotency key within IdempotencyTTL, in which case the original comment is returned.
+func (s *PostService) CreateCommentOnce(postID uint, commentID *uint, author, content, key string, viewer auth.Viewer) (*models.Comment, error) {
+	var comment *models.Comment
+	id, replayed, err := s.once(fmt.Sprintf("idempotency:comment:%d:%s:%s", postID, author, key), func() (uint, error) {
+		var err error
+		comment, err = s.CreateComment(postID, commentID, author, content, viewer)
+		if err != nil {
+			return 0, err
+		}
+		return comment.ID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		slog.Info("replaying idempotent comment creation", "comment_id", id, "author", author)
+		var original models.Comment
+		if err := s.DB.First(&original, id).Error; err != nil {
+			slog.Error("error fetching comment", "comment_id", id, "error", err)
+			return nil, err
+		}
+		return &original, nil
+	}
+	return comment, nil
+}
+
+// once runs create only for the first request with the key and records the created ID.
+// Later requests get that ID back with replayed set. The key is reserved for a short
+// while before create runs so concurrent retries cannot insert twice, and released
+// again if create fails.
+func (s *PostService) once(key string, create func() (uint, error)) (id uint, replayed bool, err error) {
+	ctx := context.Background()
+
+	reserved, err := s.RC.SetNX(ctx, key, idempotencyPending, idempotencyPendingTTL).Result()
+	if err != nil {
+		slog.Error("error reserving idempotency key", "key", key, "error", err)
+		return 0, false, err
+	}
+	if !reserved {
+		stored, err := s.RC.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			// ! The key expired in between, treat it as a new request
+			return s.once(key, create)
+		}
+		if err != nil {
+			slog.Error("error reading idempotency key", "key", key, "error", err)
+			return 0, false, err
+		}
+		if stored == idempotencyPending {
+			return 0, false, ErrRequestInProgress
+		}
+		parsed, err := strconv.ParseUint(stored, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("corrupt idempotency key %s: %w", key, err)
+		}
+		return uint(parsed), true, nil
+	}
+
+	id, err = create()
+	if err != nil {
+		s.RC.Del(ctx, key)
+		return 0, false, err
+	}
+	for attempt := 1; ; attempt++ {
+		err = s.RC.Set(ctx, key, strconv.FormatUint(uint64(id), 10), s.cfg.IdempotencyTTL).Err()
+		if err == nil {
+			return id, false, nil
+		}
+		slog.Warn("error storing idempotency key", "key", key, "attempt", attempt, "error", err)
+		if attempt == idempotencyStoreAttempts {
+			// ! The record exists, but a retry after the pending marker expires would create it again
+			return 0, false, fmt.Errorf("storing idempotency key %s: %w", key, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"log/slog"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+)
+
+// migrate updates the schema and backfills columns added after the initial release.
+// Every backfill is idempotent so it is safe to run on each start.
+func migrate(db *Database) error {
+	if err := db.AutoMigrate(&models.Post{}, &models.Comment{}, &models.Reaction{}, &models.ReactionCount{},
+		&models.Revision{}, &models.ModerationAction{}, &models.Report{}, &models.Ban{}, &models.User{},
+		&models.Mention{}, &models.Notification{}, &models.Webhook{}, &models.WebhookDelivery{},
+		&models.OutboxEvent{}); err != nil {
+		slog.Error("error during auto-migration", "error", err)
+		return ErrDatabaseMigration
+	}
+
+	// ? Latest creation time of each comment's subtree, used for ACTIVE ordering
+	if err := db.Exec(`
+		UPDATE comments SET last_activity_at = activity.latest
+		FROM (
+			WITH RECURSIVE tree AS (
+				SELECT id AS root_id, id, created_at FROM comments
+				UNION ALL
+				SELECT t.root_id, c.id, c.created_at FROM comments c JOIN tree t ON c.comment_id = t.id
+			)
+			SELECT root_id, MAX(created_at) AS latest FROM tree GROUP BY root_id
+		) AS activity
+		WHERE comments.id = activity.root_id AND comments.last_activity_at IS NULL`).Error; err != nil {
+		slog.Error("error backfilling comment activity", "error", err)
+		return ErrDatabaseMigration
+	}
+
+	// ? Comments deleted before tombstones were tracked
+	if err := db.Exec(`
+		UPDATE comments SET deleted_at = updated_at, deleted_by = ?
+		WHERE is_deleted AND deleted_at IS NULL`, models.DeletedByAuthor).Error; err != nil {
+		slog.Error("error backfilling deleted comments", "error", err)
+		return ErrDatabaseMigration
+	}
+
+	// ? Posts deleted without a viewer recorded an empty deleter instead of none
+	if err := db.Exec(`UPDATE posts SET deleted_by = NULL WHERE deleted_by = ''`).Error; err != nil {
+		slog.Error("error backfilling post deleters", "error", err)
+		return ErrDatabaseMigration
+	}
+
+	// ? Profiles for authors that posted before user accounts existed
+	if err := db.Exec(`
+		INSERT INTO users (handle, display_name, created_at)
+		SELECT author, author, MIN(created_at) FROM (
+			SELECT author, created_at FROM posts
+			UNION ALL
+			SELECT author, created_at FROM comments
+		) AS authors
+		GROUP BY author
+		ON CONFLICT (handle) DO NOTHING`).Error; err != nil {
+		slog.Error("error backfilling users", "error", err)
+		return ErrDatabaseMigration
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Ban stops an author from commenting, everywhere or on a single post, until it expires.
+type Ban struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Author    string     `gorm:"not null;index" json:"author"`
+	PostID    *uint      `gorm:"index" json:"postId"` // Nil for a global ban
+	Reason    *string    `json:"reason"`
+	BannedBy  string     `gorm:"not null" json:"bannedBy"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Nil for a permanent ban
+	CreatedAt time.Time  `json:"createdAt"`
+}
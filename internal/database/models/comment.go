@@ -4,15 +4,31 @@ import (
 	"time"
 )
 
+// DeletionActor tells who deleted a comment.
+type DeletionActor string
+
+const (
+	DeletedByAuthor    DeletionActor = "AUTHOR"
+	DeletedByModerator DeletionActor = "MODERATOR"
+)
+
 // Comment represents a comment on a post.
 type Comment struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	PostID    uint      `gorm:"not null" json:"postId"`
-	CommentID *uint     `gorm:"index" json:"commentId"` // ID of the parent comment
-	Author    string    `gorm:"not null" json:"author"`
-	Content   string    `gorm:"not null;size:2000" json:"content"`
-	IsDeleted bool      `gorm:"not null" json:"isDeleted"`
-	Replies   []Comment `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"replies"`
-	CreatedAt time.Time `gorm:"index" json:"createdAt"`
-	UpdatedAt time.Time `gorm:"index" json:"updatedAt"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	PostID         uint           `gorm:"not null;index" json:"postId"`
+	CommentID      *uint          `gorm:"index" json:"commentId"` // ID of the parent comment
+	Author         string         `gorm:"not null" json:"author"`
+	Content        string         `gorm:"not null;size:2000" json:"content"`
+	IsDeleted      bool           `gorm:"not null" json:"isDeleted"`
+	DeletedAt      *time.Time     `json:"deletedAt"`
+	DeletedBy      *DeletionActor `json:"deletedBy"`
+	Score          int            `gorm:"not null;default:0;index" json:"score"`
+	Edited         bool           `gorm:"not null;default:false" json:"edited"`
+	Version        int            `gorm:"not null;default:1" json:"version"` // Incremented by every update
+	Status         ContentStatus  `gorm:"not null;default:PUBLISHED;index" json:"status"`
+	Locked         bool           `gorm:"not null;default:false" json:"locked"` // Locked threads accept no new replies anywhere below
+	Replies        []Comment      `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"replies"`
+	LastActivityAt time.Time      `gorm:"index" json:"lastActivityAt"` // Latest creation time in the comment's subtree
+	CreatedAt      time.Time      `gorm:"index" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"index" json:"updatedAt"`
 }
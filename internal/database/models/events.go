@@ -0,0 +1,69 @@
+package models
+
+// CommentEvent is a change to the comments of a post delivered to subscribers.
+// Sequence numbers increase monotonically per post.
+type CommentEvent interface {
+	IsCommentEvent()
+	GetSequence() int64
+}
+
+// CommentAdded is published when a comment is created.
+type CommentAdded struct {
+	Sequence int64    `json:"sequence"`
+	Comment  *Comment `json:"comment"`
+}
+
+// CommentUpdated is published when the content of a comment changes.
+type CommentUpdated struct {
+	Sequence int64    `json:"sequence"`
+	Comment  *Comment `json:"comment"`
+}
+
+// CommentDeleted is published when a comment becomes a tombstone.
+type CommentDeleted struct {
+	Sequence int64    `json:"sequence"`
+	Comment  *Comment `json:"comment"`
+}
+
+func (CommentAdded) IsCommentEvent()   {}
+func (CommentUpdated) IsCommentEvent() {}
+func (CommentDeleted) IsCommentEvent() {}
+
+func (e CommentAdded) GetSequence() int64   { return e.Sequence }
+func (e CommentUpdated) GetSequence() int64 { return e.Sequence }
+func (e CommentDeleted) GetSequence() int64 { return e.Sequence }
+
+// PostEvent is a change to a post delivered to subscribers.
+type PostEvent interface {
+	IsPostEvent()
+}
+
+// PostCreated is published when a post is created.
+type PostCreated struct {
+	EventID string `json:"eventId"` // Outbox event that caused it
+	Post    *Post  `json:"post"`
+}
+
+// PostUpdated is published when a post is edited or restored.
+type PostUpdated struct {
+	EventID string `json:"eventId"` // Outbox event that caused it
+	Post    *Post  `json:"post"`
+}
+
+// PostDeleted is published when a post is soft-deleted.
+type PostDeleted struct {
+	EventID string `json:"eventId"` // Outbox event that caused it
+	Post    *Post  `json:"post"`
+}
+
+// CommentsToggled is published when comments are enabled or disabled on a post.
+type CommentsToggled struct {
+	EventID         string `json:"eventId"` // Outbox event that caused it
+	PostID          uint   `json:"postId"`
+	CommentsEnabled bool   `json:"commentsEnabled"`
+}
+
+func (PostCreated) IsPostEvent()     {}
+func (PostUpdated) IsPostEvent()     {}
+func (PostDeleted) IsPostEvent()     {}
+func (CommentsToggled) IsPostEvent() {}
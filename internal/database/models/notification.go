@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Mention is a handle referenced with @ in the text of a post or comment.
+type Mention struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	TargetType TargetType `gorm:"not null;uniqueIndex:idx_mention_key" json:"targetType"`
+	TargetID   uint       `gorm:"not null;uniqueIndex:idx_mention_key" json:"targetId"`
+	Handle     string     `gorm:"not null;uniqueIndex:idx_mention_key;index" json:"handle"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// NotificationKind is why a user was notified.
+type NotificationKind string
+
+const (
+	NotificationMention NotificationKind = "MENTION"
+	NotificationReply   NotificationKind = "REPLY"
+)
+
+// Notification tells a user that someone mentioned them or replied to their comment.
+// The target is the post or comment that caused it.
+type Notification struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	Recipient  string           `gorm:"not null;uniqueIndex:idx_notification_key;index:idx_notification_inbox" json:"recipient"`
+	Kind       NotificationKind `gorm:"not null;uniqueIndex:idx_notification_key" json:"kind"`
+	TargetType TargetType       `gorm:"not null;uniqueIndex:idx_notification_key" json:"targetType"`
+	TargetID   uint             `gorm:"not null;uniqueIndex:idx_notification_key" json:"targetId"`
+	PostID     uint             `gorm:"not null;index" json:"postId"`
+	Actor      string           `gorm:"not null" json:"actor"`
+	EventID    *string          `json:"eventId"` // Outbox event that created it, nil for notifications created before it was recorded
+	Read       bool             `gorm:"not null;default:false;index:idx_notification_inbox" json:"read"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
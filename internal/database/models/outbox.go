@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxKind is the kind of change recorded in the outbox.
+type OutboxKind string
+
+const (
+	OutboxPostChanged       OutboxKind = "post_changed" // Created, edited or moderated
+	OutboxPostDeleted       OutboxKind = "post_deleted"
+	OutboxPostRestored      OutboxKind = "post_restored"
+	OutboxCommentsToggled   OutboxKind = "comments_toggled"
+	OutboxCommentChanged    OutboxKind = "comment_changed" // Created, edited or moderated
+	OutboxCommentDeleted    OutboxKind = "comment_deleted"
+	OutboxThreadLockChanged OutboxKind = "thread_lock_changed"
+	OutboxReactionsChanged  OutboxKind = "reactions_changed"
+	OutboxProfileUpdated    OutboxKind = "profile_updated"
+)
+
+// OutboxEvent is a change written in the same transaction as the data it describes.
+// The relay invalidates caches and notifies subscribers and webhooks for it, retrying
+// until it succeeds, so consumers may see an event more than once and use EventID
+// to tell duplicates apart.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventID       string     `gorm:"not null;uniqueIndex" json:"eventId"`
+	Kind          OutboxKind `gorm:"not null" json:"kind"`
+	PostID        uint       `gorm:"not null;default:0;index" json:"postId"` // Zero for changes outside posts, events of a post are relayed in order
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     *string    `json:"lastError"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_due" json:"nextAttemptAt"`
+	PublishedAt   *time.Time `gorm:"index:idx_outbox_due" json:"publishedAt"` // Nil until relayed
+	CreatedAt     time.Time  `json:"createdAt"`
+}
@@ -2,16 +2,25 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Post represents a blog post.
 type Post struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	Title           string    `gorm:"not null" json:"title"`
-	Content         string    `gorm:"not null" json:"content"`
-	Author          string    `gorm:"not null" json:"author"`
-	CommentsEnabled bool      `gorm:"not null" json:"commentsEnabled"`
-	Comments        []Comment `gorm:"foreignKey:PostID" json:"comments"`
-	CreatedAt       time.Time `gorm:"index" json:"createdAt"`
-	UpdatedAt       time.Time `gorm:"index" json:"updatedAt"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Title           string         `gorm:"not null" json:"title"`
+	Content         string         `gorm:"not null" json:"content"`
+	Author          string         `gorm:"not null;index" json:"author"`
+	CommentsEnabled bool           `gorm:"not null;index" json:"commentsEnabled"`
+	Score           int            `gorm:"not null;default:0;index" json:"score"`
+	Edited          bool           `gorm:"not null;default:false" json:"edited"`
+	Version         int            `gorm:"not null;default:1" json:"version"` // Incremented by every update
+	Status          ContentStatus  `gorm:"not null;default:PUBLISHED;index" json:"status"`
+	Comments        []Comment      `gorm:"foreignKey:PostID" json:"comments"`
+	CreatedAt       time.Time      `gorm:"index" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"index" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	DeletedBy       *string        `json:"deletedBy"`
+	EventID         *string        `gorm:"-" json:"eventId,omitempty"` // Outbox event that delivered the post to a subscriber
 }
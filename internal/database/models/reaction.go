@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// ReactionKind is a vote or an emoji reaction.
+type ReactionKind string
+
+const (
+	ReactionUpvote    ReactionKind = "UPVOTE"
+	ReactionDownvote  ReactionKind = "DOWNVOTE"
+	ReactionLike      ReactionKind = "LIKE"
+	ReactionHeart     ReactionKind = "HEART"
+	ReactionLaugh     ReactionKind = "LAUGH"
+	ReactionSurprised ReactionKind = "SURPRISED"
+	ReactionSad       ReactionKind = "SAD"
+	ReactionAngry     ReactionKind = "ANGRY"
+)
+
+// Reaction is a single user's reaction to a post or a comment.
+type Reaction struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	TargetType TargetType   `gorm:"not null;uniqueIndex:idx_reaction_key" json:"targetType"`
+	TargetID   uint         `gorm:"not null;uniqueIndex:idx_reaction_key" json:"targetId"`
+	User       string       `gorm:"not null;uniqueIndex:idx_reaction_key" json:"user"`
+	Kind       ReactionKind `gorm:"not null;uniqueIndex:idx_reaction_key" json:"kind"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+// ReactionCount is the denormalized number of reactions of one kind on a target.
+type ReactionCount struct {
+	TargetType TargetType   `gorm:"primaryKey" json:"targetType"`
+	TargetID   uint         `gorm:"primaryKey" json:"targetId"`
+	Kind       ReactionKind `gorm:"primaryKey" json:"kind"`
+	Count      int          `gorm:"not null;default:0" json:"count"`
+}
+
+// ReactionSummary is the current score and reaction counts of a target.
+type ReactionSummary struct {
+	TargetType TargetType      `json:"targetType"`
+	TargetID   uint            `json:"targetId"`
+	PostID     uint            `json:"postId"`
+	Score      int             `json:"score"`
+	Counts     []ReactionCount `json:"counts"`
+	EventID    *string         `json:"eventId,omitempty"` // Outbox event that delivered the summary to a subscriber
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// ReportReason is why a reader flagged a post or comment.
+type ReportReason string
+
+const (
+	ReportSpam           ReportReason = "SPAM"
+	ReportAbuse          ReportReason = "ABUSE"
+	ReportHarassment     ReportReason = "HARASSMENT"
+	ReportMisinformation ReportReason = "MISINFORMATION"
+	ReportOther          ReportReason = "OTHER"
+)
+
+// Report is a reader flagging a post or comment for moderators.
+type Report struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	TargetType TargetType   `gorm:"not null;uniqueIndex:idx_report_key" json:"targetType"`
+	TargetID   uint         `gorm:"not null;uniqueIndex:idx_report_key" json:"targetId"`
+	Reporter   string       `gorm:"not null;uniqueIndex:idx_report_key" json:"reporter"`
+	ReporterIP *string      `json:"-"` // Client IP the report came from, reports from one IP count once towards the hold threshold
+	Reason     ReportReason `gorm:"not null" json:"reason"`
+	Note       *string      `gorm:"size:1000" json:"note"`
+	Resolved   bool         `gorm:"not null;default:false;index" json:"resolved"` // Set once a moderator decides on the target
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+// ReportedItem is the tally of open reports about a post or comment.
+type ReportedItem struct {
+	TargetType     TargetType `json:"targetType"`
+	TargetID       uint       `json:"targetId"`
+	ReportCount    int        `json:"reportCount"`
+	LastReportedAt time.Time  `json:"lastReportedAt"`
+}
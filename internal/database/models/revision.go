@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Revision is a snapshot of a post or a comment taken before it was edited.
+type Revision struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	TargetType TargetType `gorm:"not null;index:idx_revision_target" json:"targetType"`
+	TargetID   uint       `gorm:"not null;index:idx_revision_target" json:"targetId"`
+	Title      *string    `json:"title"` // Only set for posts
+	Content    string     `gorm:"not null" json:"content"`
+	EditedBy   string     `gorm:"not null" json:"editedBy"` // Who replaced this version
+	CreatedAt  time.Time  `gorm:"index" json:"createdAt"`
+}
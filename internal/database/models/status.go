@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ContentStatus is the moderation state of a post or comment.
+type ContentStatus string
+
+const (
+	StatusPublished ContentStatus = "PUBLISHED"
+	StatusPending   ContentStatus = "PENDING" // ? Held for review, visible to the author and moderators
+	StatusRejected  ContentStatus = "REJECTED"
+	StatusHidden    ContentStatus = "HIDDEN"
+)
+
+// ModerationAction records a moderator changing the status of a post or comment.
+type ModerationAction struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	TargetType TargetType    `gorm:"not null;index:idx_moderation_target" json:"targetType"`
+	TargetID   uint          `gorm:"not null;index:idx_moderation_target" json:"targetId"`
+	Status     ContentStatus `gorm:"not null" json:"status"` // Status set by the action
+	Moderator  string        `gorm:"not null" json:"moderator"`
+	Reason     *string       `json:"reason"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+// ModerationItem is a post or comment in the moderation queue.
+type ModerationItem interface {
+	IsModerationItem()
+}
+
+func (Post) IsModerationItem()    {}
+func (Comment) IsModerationItem() {}
@@ -0,0 +1,9 @@
+package models
+
+// TargetType is the kind of entity reactions, revisions and other records refer to.
+type TargetType string
+
+const (
+	TargetPost    TargetType = "POST"
+	TargetComment TargetType = "COMMENT"
+)
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// User is the profile of an author. Posts and comments refer to it by handle.
+type User struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Handle      string    `gorm:"not null;uniqueIndex" json:"handle"`
+	DisplayName string    `gorm:"not null" json:"displayName"`
+	Bio         *string   `gorm:"size:500" json:"bio"`
+	AvatarURL   *string   `json:"avatarUrl"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
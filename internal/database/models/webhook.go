@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WebhookEvent is a post or comment lifecycle event that webhooks subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookPostCreated    WebhookEvent = "POST_CREATED"
+	WebhookPostUpdated    WebhookEvent = "POST_UPDATED"
+	WebhookPostDeleted    WebhookEvent = "POST_DELETED"
+	WebhookCommentCreated WebhookEvent = "COMMENT_CREATED"
+	WebhookCommentUpdated WebhookEvent = "COMMENT_UPDATED"
+	WebhookCommentDeleted WebhookEvent = "COMMENT_DELETED"
+)
+
+// Name returns the event name sent to receivers, e.g. post.created.
+func (e WebhookEvent) Name() string {
+	return strings.ReplaceAll(strings.ToLower(string(e)), "_", ".")
+}
+
+// WebhookEvents is a set of events stored as a comma separated list.
+type WebhookEvents []WebhookEvent
+
+// Has reports whether the set contains the event.
+func (e WebhookEvents) Has(event WebhookEvent) bool {
+	for _, candidate := range e {
+		if candidate == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	names := make([]string, len(e))
+	for i, event := range e {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ","), nil
+}
+
+func (e *WebhookEvents) Scan(value interface{}) error {
+	var list string
+	switch v := value.(type) {
+	case string:
+		list = v
+	case []byte:
+		list = string(v)
+	default:
+		return fmt.Errorf("unsupported type for webhook events: %T", value)
+	}
+
+	*e = nil
+	for _, name := range strings.Split(list, ",") {
+		if name != "" {
+			*e = append(*e, WebhookEvent(name))
+		}
+	}
+	return nil
+}
+
+// Webhook is an endpoint receiving signed JSON POSTs for the events it subscribes to.
+type Webhook struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	URL       string        `gorm:"not null" json:"url"`
+	Secret    string        `gorm:"not null" json:"-"` // Key of the HMAC signature, shown once on creation
+	Events    WebhookEvents `gorm:"type:text;not null" json:"events"`
+	Active    bool          `gorm:"not null;default:true" json:"active"`
+	CreatedBy string        `gorm:"not null" json:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// DeliveryStatus is the state of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING" // Waiting for its first attempt or a retry
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryDead      DeliveryStatus = "DEAD" // Out of attempts, retried only on request
+)
+
+// WebhookDelivery is one event to send to one webhook. Pending deliveries form the outbox
+// drained by the webhook worker, and the rest are kept as the delivery log.
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	WebhookID      uint           `gorm:"not null;uniqueIndex:idx_delivery_key" json:"webhookId"`
+	Webhook        Webhook        `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	EventID        string         `gorm:"not null;uniqueIndex:idx_delivery_key" json:"eventId"` // Shared by the deliveries of one event so receivers can drop duplicates
+	Event          WebhookEvent   `gorm:"not null" json:"event"`
+	Payload        string         `gorm:"type:text;not null" json:"payload"`
+	Status         DeliveryStatus `gorm:"not null;index:idx_delivery_due" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time      `gorm:"not null;index:idx_delivery_due" json:"nextAttemptAt"`
+	ResponseStatus *int           `json:"responseStatus"`
+	LastError      *string        `json:"lastError"`
+	DeliveredAt    *time.Time     `json:"deliveredAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/config"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/moderation"
+	"log/slog"
+)
+
+const recentContentLimit = 20 // ? Latest items of an author compared by the duplicates filter
+
+var ErrUnknownFilter = errors.New("unknown moderation filter")
+
+// newModerator builds the moderation pipeline from the filters enabled in the configuration.
+func newModerator(s *PostService, cfg config.ModerationConfig) (*moderation.Pipeline, error) {
+	filters := make([]moderation.Filter, 0, len(cfg.Filters))
+	for _, name := range cfg.Filters {
+		switch name {
+		case "banned_words":
+			filters = append(filters, moderation.NewBannedWords(cfg.BannedWords))
+		case "links":
+			filters = append(filters, moderation.LinkLimit{Max: cfg.MaxLinks})
+		case "repeated_chars":
+			filters = append(filters, moderation.RepeatedCharacters{MaxRun: cfg.MaxRepeatedChars})
+		case "duplicates":
+			filters = append(filters, moderation.DuplicateContent{Recent: s.recentContent, Window: cfg.DuplicateWindow})
+		case "":
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFilter, name)
+		}
+	}
+	return moderation.NewPipeline(filters...), nil
+}
+
+// moderate runs the moderation pipeline on new or edited content.
+// A rejection is returned as a field error so it reaches the author as a user error.
+func (s *PostService) moderate(content moderation.Content) (moderation.Decision, error) {
+	if s.moderator == nil {
+		return moderation.Allow, nil
+	}
+	decision := s.moderator.Moderate(content)
+	if decision.Verdict == moderation.VerdictReject {
+		return decision, &FieldError{Field: decision.Field, Err: decision.Err()}
+	}
+	return decision, nil
+}
+
+// recentContent returns the latest posts or comments of an author for the duplicates filter.
+func (s *PostService) recentContent(kind moderation.Kind, author string, since time.Time, excludeID uint) ([]string, error) {
+	var contents []string
+	query := s.DB.Where("author = ? AND created_at > ? AND id <> ?", author, since, excludeID).
+		Order("created_at DESC").Limit(recentContentLimit)
+
+	var err error
+	if kind == moderation.KindPost {
+		err = query.Model(&models.Post{}).Pluck("content", &contents).Error
+	} else {
+		err = query.Model(&models.Comment{}).Where("NOT is_deleted").Pluck("content", &contents).Error
+	}
+	if err != nil {
+		slog.Error("error fetching recent content", "kind", kind, "author", author, "error", err)
+		return nil, err
+	}
+	return contents, nil
+}
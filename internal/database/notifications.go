@@ -0,0 +1,171 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+const (
+	MaxMentions                 = 10 // ? Handles notified per post or comment, further mentions are ignored
+	DefaultNotificationPageSize = 20
+)
+
+// ? A mention starts at a word boundary so e-mail addresses are not taken for handles
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w+(?:[.-]\w+)*)`)
+
+// ParseMentions returns the distinct handles mentioned with @ in the text, in order of appearance.
+func ParseMentions(text string) []string {
+	var handles []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		handle := match[1]
+		if seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+		if len(handles) == MaxMentions {
+			break
+		}
+	}
+	return handles
+}
+
+// syncMentions stores the users mentioned in the text of a post or comment, replacing
+// the mentions of its previous version. Handles without a profile and the author are skipped.
+func syncMentions(tx *gorm.DB, target models.TargetType, targetID uint, author string, text ...string) error {
+	var known []string
+	if handles := ParseMentions(strings.Join(text, "\n")); len(handles) > 0 {
+		err := tx.Model(&models.User{}).Where("handle IN ? AND handle <> ?", handles, author).Pluck("handle", &known).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	stale := tx.Where("target_type = ? AND target_id = ?", target, targetID)
+	if len(known) > 0 {
+		stale = stale.Where("handle NOT IN ?", known)
+	}
+	if err := stale.Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if len(known) == 0 {
+		return nil
+	}
+
+	mentions := make([]models.Mention, len(known))
+	for i, handle := range known {
+		mentions[i] = models.Mention{TargetType: target, TargetID: targetID, Handle: handle}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error
+}
+
+// notifyPost notifies the users mentioned in a published post.
+func (s *PostService) notifyPost(eventID string, post *models.Post) error {
+	return s.notify(eventID, models.TargetPost, post.ID, post.ID, post.Author, "")
+}
+
+// notifyComment notifies the users mentioned in a published comment and, for a reply,
+// the author of the parent comment.
+func (s *PostService) notifyComment(eventID string, comment *models.Comment) error {
+	var parentAuthor string
+	if comment.CommentID != nil {
+		err := s.DB.Model(&models.Comment{}).Select("author").Where("id = ? AND NOT is_deleted", *comment.CommentID).Scan(&parentAuthor).Error
+		if err != nil {
+			slog.Warn("failed to fetch parent comment author", "comment_id", *comment.CommentID, "error", err)
+			return err
+		}
+	}
+	return s.notify(eventID, models.TargetComment, comment.ID, comment.PostID, comment.Author, parentAuthor)
+}
+
+// notify creates the notifications caused by published content and delivers them to subscribers.
+// Each recipient is notified once per target, so edits and re-publishing only reach new mentions.
+// A parent author who is also mentioned gets a single reply notification.
+// Notifications keep the ID of the outbox event that created them.
+func (s *PostService) notify(eventID string, target models.TargetType, targetID, postID uint, actor, parentAuthor string) error {
+	var mentioned []string
+	if err := s.DB.Model(&models.Mention{}).Where("target_type = ? AND target_id = ?", target, targetID).
+		Pluck("handle", &mentioned).Error; err != nil {
+		slog.Warn("failed to fetch mentions", "target_type", target, "target_id", targetID, "error", err)
+		return err
+	}
+
+	recipients := make(map[string]models.NotificationKind, len(mentioned)+1)
+	for _, handle := range mentioned {
+		recipients[handle] = models.NotificationMention
+	}
+	if parentAuthor != "" {
+		recipients[parentAuthor] = models.NotificationReply
+	}
+	delete(recipients, actor)
+
+	for recipient, kind := range recipients {
+		notification := &models.Notification{
+			Recipient:  recipient,
+			Kind:       kind,
+			TargetType: target,
+			TargetID:   targetID,
+			PostID:     postID,
+			Actor:      actor,
+			EventID:    &eventID,
+		}
+		result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+		if result.Error != nil {
+			slog.Warn("failed to create notification", "recipient", recipient, "kind", kind, "error", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			s.Notifications.Publish(recipient, notification)
+		}
+	}
+	return nil
+}
+
+// GetNotifications returns one page of the notifications of a user, newest first.
+// The after cursor is the ID of the last notification of the previous page.
+func (s *PostService) GetNotifications(recipient string, first *int, after *uint, unreadOnly bool) ([]models.Notification, error) {
+	limit := DefaultNotificationPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidPageSize
+		}
+		limit = *first
+	}
+
+	query := s.DB.Where("recipient = ?", recipient)
+	if unreadOnly {
+		query = query.Where("NOT read")
+	}
+	if after != nil {
+		query = query.Where("id < ?", *after)
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		slog.Error("error fetching notifications", "recipient", recipient, "error", err)
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkNotificationsRead marks notifications of a user as read, all unread ones when ids is nil.
+// It returns how many notifications changed.
+func (s *PostService) MarkNotificationsRead(recipient string, ids []uint) (int64, error) {
+	query := s.DB.Model(&models.Notification{}).Where("recipient = ? AND NOT read", recipient)
+	if ids != nil {
+		query = query.Where("id IN ?", ids)
+	}
+
+	result := query.Update("read", true)
+	if result.Error != nil {
+		slog.Error("error marking notifications read", "recipient", recipient, "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	many := make([]string, MaxMentions+2)
+	for i := range many {
+		many[i] = fmt.Sprintf("user%d", i)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "none", text: "no mentions here", want: nil},
+		{name: "single", text: "@alice", want: []string{"alice"}},
+		{name: "in order", text: "thanks @bob and @alice", want: []string{"bob", "alice"}},
+		{name: "punctuation", text: "(@alice), @bob. @carol!", want: []string{"alice", "bob", "carol"}},
+		{name: "dotted and dashed", text: "cc @jane.doe @john-smith", want: []string{"jane.doe", "john-smith"}},
+		{name: "email", text: "mail alice@example.com", want: nil},
+		{name: "email and mention", text: "@bob wrote to alice@example.com", want: []string{"bob"}},
+		{name: "double at", text: "@@alice", want: nil},
+		{name: "duplicates", text: "@alice @bob @alice @bob", want: []string{"alice", "bob"}},
+		{name: "capped", text: "@" + strings.Join(many, " @"), want: many[:MaxMentions]},
+		{name: "duplicates not counted", text: "@user0 @user0 @" + strings.Join(many, " @"), want: many[:MaxMentions]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
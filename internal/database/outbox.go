@@ -0,0 +1,291 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/config"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/webhook"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+// ? Time the writer has to relay its own events, and the lease of events claimed
+// ? by the relay worker, before another relay may pick them up
+const outboxLease = 30 * time.Second
+
+// outboxPayload is the JSON stored with an outbox event. Only the fields of its kind are set.
+type outboxPayload struct {
+	Post      *models.Post            `json:"post,omitempty"`
+	Comment   *models.Comment         `json:"comment,omitempty"`
+	Previous  models.ContentStatus    `json:"previous,omitempty"` // Status before the change, empty for new content
+	Reactions *models.ReactionSummary `json:"reactions,omitempty"`
+	User      *models.User            `json:"user,omitempty"`
+}
+
+// addOutbox records a change in the outbox as part of the transaction that makes it.
+func addOutbox(tx *gorm.DB, kind models.OutboxKind, postID uint, payload outboxPayload) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	eventID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.OutboxEvent{
+		EventID:       eventID,
+		Kind:          kind,
+		PostID:        postID,
+		Payload:       string(data),
+		NextAttemptAt: time.Now().Add(outboxLease),
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// relayOutbox relays events right after their transaction committed, so the writer
+// reads its own changes. Events that fail, and events queued behind an earlier event
+// of their post, are left to the relay worker.
+func (s *PostService) relayOutbox(events ...*models.OutboxEvent) {
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		blocked, err := s.outboxBlocked(event)
+		if err != nil {
+			slog.Warn("failed to check the order of outbox event, leaving it to the relay worker",
+				"event_id", event.EventID, "kind", event.Kind, "error", err)
+			continue
+		}
+		if blocked {
+			// ? Hand the event to the next poll of the relay worker instead of after the lease
+			slog.Info("outbox event waits for an earlier event of its post", "event_id", event.EventID, "post_id", event.PostID)
+			if err := s.DB.Model(event).Update("next_attempt_at", time.Now()).Error; err != nil {
+				slog.Warn("failed to hand outbox event to the relay worker", "event_id", event.EventID, "error", err)
+			}
+			continue
+		}
+		if err := s.dispatchOutbox(event); err != nil {
+			slog.Warn("failed to relay outbox event, leaving it to the relay worker",
+				"event_id", event.EventID, "kind", event.Kind, "error", err)
+			continue
+		}
+		if err := s.DB.Model(event).Update("published_at", time.Now()).Error; err != nil {
+			slog.Warn("failed to mark outbox event as relayed", "event_id", event.EventID, "error", err)
+		}
+	}
+}
+
+// outboxBlocked reports whether an earlier event of the same post was not relayed yet.
+// Events of a post are relayed in order, so such an event has to go first.
+func (s *PostService) outboxBlocked(event *models.OutboxEvent) (bool, error) {
+	if event.PostID == 0 {
+		return false, nil
+	}
+	var earlier int64
+	err := s.DB.Model(&models.OutboxEvent{}).
+		Where("post_id = ? AND id < ? AND published_at IS NULL", event.PostID, event.ID).
+		Count(&earlier).Error
+	return earlier > 0, err
+}
+
+// dispatchOutbox invalidates the caches affected by an event and notifies subscribers
+// and webhooks. Every step is safe to repeat for the same event.
+func (s *PostService) dispatchOutbox(event *models.OutboxEvent) error {
+	var payload outboxPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return err
+	}
+	if event.Kind == models.OutboxProfileUpdated {
+		return s.deleteCacheKeys(fmt.Sprintf("user:%s", payload.User.Handle))
+	}
+	if err := s.invalidatePostCache(event.PostID); err != nil {
+		return err
+	}
+
+	switch event.Kind {
+	case models.OutboxPostChanged:
+		return s.publishPostStatus(event.EventID, payload.Post, payload.Previous)
+	case models.OutboxPostDeleted:
+		if payload.Post.Status == models.StatusPublished {
+			return s.publishPostEvent(event.EventID, event.PostID, &models.PostDeleted{EventID: event.EventID, Post: payload.Post})
+		}
+	case models.OutboxPostRestored:
+		if payload.Post.Status == models.StatusPublished {
+			return s.publishPostEvent(event.EventID, event.PostID, &models.PostUpdated{EventID: event.EventID, Post: payload.Post})
+		}
+	case models.OutboxCommentsToggled:
+		if payload.Post.Status == models.StatusPublished {
+			return s.publishPostEvent(event.EventID, event.PostID, &models.CommentsToggled{
+				EventID:         event.EventID,
+				PostID:          payload.Post.ID,
+				CommentsEnabled: payload.Post.CommentsEnabled,
+			})
+		}
+	case models.OutboxCommentChanged:
+		return s.publishCommentStatus(event.EventID, payload.Comment, payload.Previous)
+	case models.OutboxCommentDeleted:
+		if payload.Comment.Status == models.StatusPublished {
+			return s.publishCommentEvent(event.EventID, event.PostID, func(sequence int64) models.CommentEvent {
+				return &models.CommentDeleted{Sequence: sequence, Comment: payload.Comment}
+			})
+		}
+	case models.OutboxReactionsChanged:
+		payload.Reactions.EventID = &event.EventID
+		s.ReactionsChanged.Publish(strconv.FormatUint(uint64(event.PostID), 10), payload.Reactions)
+	}
+	return nil
+}
+
+// invalidatePostCache drops the cached listings, the post and its comments.
+func (s *PostService) invalidatePostCache(postID uint) error {
+	keys, err := s.RC.Keys(context.Background(), "posts*").Result()
+	if err != nil {
+		return err
+	}
+	keys = append(keys, fmt.Sprintf("post:%d", postID), fmt.Sprintf("comments:%d", postID))
+	return s.deleteCacheKeys(keys...)
+}
+
+// deleteCacheKeys removes cache entries, reporting failures unlike clearCache.
+func (s *PostService) deleteCacheKeys(keys ...string) error {
+	return s.RC.Del(context.Background(), keys...).Err()
+}
+
+// StartOutboxRelay periodically relays the outbox events their writer could not relay,
+// and removes relayed events older than the retention. It stops when ctx is done.
+func (s *PostService) StartOutboxRelay(ctx context.Context, cfg config.OutboxConfig) {
+	ticker := time.NewTicker(cfg.PollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.relayPendingOutbox(cfg); err != nil {
+					slog.Error("error relaying outbox", "error", err)
+				}
+				if err := s.DB.Where("published_at < ?", time.Now().Add(-cfg.Retention)).Delete(&models.OutboxEvent{}).Error; err != nil {
+					slog.Error("error removing relayed outbox events", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// relayPendingOutbox claims up to a batch of due events and relays them in order.
+// Failed events are retried with exponential backoff, and the later events of their
+// post wait for the retry without using up attempts of their own.
+func (s *PostService) relayPendingOutbox(cfg config.OutboxConfig) error {
+	events, err := s.claimOutbox(cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	retries := make(map[uint]time.Time) // ? Retry time of the failed event of each post
+	for i := range events {
+		event := &events[i]
+		now := time.Now()
+		var updates map[string]interface{}
+		if retry, failed := retries[event.PostID]; failed {
+			updates = map[string]interface{}{"next_attempt_at": retry}
+		} else if err := s.dispatchOutbox(event); err != nil {
+			attempts := event.Attempts + 1
+			retry := now.Add(webhook.Backoff(attempts, cfg.PollInterval, cfg.BackoffMax))
+			updates = map[string]interface{}{
+				"attempts":        attempts,
+				"last_error":      err.Error(),
+				"next_attempt_at": retry,
+			}
+			if event.PostID != 0 {
+				retries[event.PostID] = retry
+			}
+			slog.Warn("failed to relay outbox event", "event_id", event.EventID, "kind", event.Kind, "attempts", attempts, "error", err)
+		} else {
+			updates = map[string]interface{}{"published_at": now, "last_error": nil}
+		}
+		if err := s.DB.Model(event).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimOutbox locks the due events that were not relayed yet and pushes their next
+// attempt past the lease, so concurrent relays do not pick them up as well. Events
+// queued behind an earlier event of their post outside the batch are not claimed.
+func (s *PostService) claimOutbox(batch int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").Limit(batch).Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		var postIDs []uint
+		for i, event := range events {
+			ids[i] = event.ID
+			if event.PostID != 0 {
+				postIDs = append(postIDs, event.PostID)
+			}
+		}
+
+		// ? The first pending event of each post outside the batch, such as one backing
+		// ? off or claimed by another relay, holds back the later events of its post
+		var blockers []models.OutboxEvent
+		if len(postIDs) > 0 {
+			err = tx.Select("DISTINCT ON (post_id) post_id, id, next_attempt_at").
+				Where("post_id IN ? AND published_at IS NULL AND id NOT IN ?", postIDs, ids).
+				Order("post_id, id").Find(&blockers).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(blockers) > 0 {
+			firstPending := make(map[uint]models.OutboxEvent, len(blockers))
+			for _, blocker := range blockers {
+				firstPending[blocker.PostID] = blocker
+			}
+			claimed := events[:0]
+			for _, event := range events {
+				blocker, ok := firstPending[event.PostID]
+				if !ok || event.ID < blocker.ID {
+					claimed = append(claimed, event)
+					continue
+				}
+				// ? Wait for the earlier event instead of coming up in every poll
+				retry := blocker.NextAttemptAt
+				if retry.Before(now) {
+					retry = now
+				}
+				if err := tx.Model(&event).Update("next_attempt_at", retry).Error; err != nil {
+					return err
+				}
+			}
+			events = claimed
+			ids = ids[:0]
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(outboxLease)).Error
+	})
+	return events, err
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCursor   = errors.New("cursor does not point to a comment in this list")
+	ErrCursorOrder     = errors.New("cursor was made for a different comment order")
+	ErrInvalidPageSize = errors.New("page size cannot be negative")
+)
+
+// PostSort enumerates the orderings supported when listing posts.
+type PostSort string
+
+const (
+	SortCreatedAt    PostSort = "created_at"
+	SortUpdatedAt    PostSort = "updated_at"
+	SortCommentCount PostSort = "comment_count"
+)
+
+// commentCountExpr counts comments of the current post row, served by the comments.post_id index.
+const commentCountExpr = "(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id)"
+
+// PostQuery describes filtering and ordering options for listing posts.
+type PostQuery struct {
+	Author          *string
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	CommentsEnabled *bool
+	HasComments     *bool
+	SortBy          PostSort
+	Descending      bool
+	IncludeDeleted  bool // ? Soft-deleted posts are listed only on request
+}
+
+// normalize returns a copy of the query with trimmed values and defaults applied.
+func (q PostQuery) normalize() PostQuery {
+	if q.Author != nil {
+		author := strings.TrimSpace(*q.Author)
+		q.Author = &author
+	}
+	if q.CreatedAfter != nil {
+		after := q.CreatedAfter.UTC()
+		q.CreatedAfter = &after
+	}
+	if q.CreatedBefore != nil {
+		before := q.CreatedBefore.UTC()
+		q.CreatedBefore = &before
+	}
+	switch q.SortBy {
+	case SortCreatedAt, SortUpdatedAt, SortCommentCount:
+	default:
+		q.SortBy = SortCreatedAt
+	}
+	return q
+}
+
+// cacheKey builds a deterministic cache key from the normalized query.
+// The default listing keeps the plain "posts" key.
+func (q PostQuery) cacheKey() string {
+	q = q.normalize()
+	if q == (PostQuery{SortBy: SortCreatedAt}) {
+		return "posts"
+	}
+
+	parts := make([]string, 0, 7)
+	if q.Author != nil {
+		// ? Quoted so an author containing the separator cannot pose as other filters
+		parts = append(parts, "author="+strconv.Quote(*q.Author))
+	}
+	if q.CreatedAfter != nil {
+		parts = append(parts, "after="+q.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if q.CreatedBefore != nil {
+		parts = append(parts, "before="+q.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	if q.CommentsEnabled != nil {
+		parts = append(parts, fmt.Sprintf("enabled=%t", *q.CommentsEnabled))
+	}
+	if q.HasComments != nil {
+		parts = append(parts, fmt.Sprintf("hascomments=%t", *q.HasComments))
+	}
+	if q.IncludeDeleted {
+		parts = append(parts, "deleted=true")
+	}
+	direction := "asc"
+	if q.Descending {
+		direction = "desc"
+	}
+	parts = append(parts, fmt.Sprintf("sort=%s:%s", q.SortBy, direction))
+
+	return "posts:" + strings.Join(parts, "|")
+}
+
+// apply adds the query conditions and ordering to a posts statement.
+func (q PostQuery) apply(db *gorm.DB) *gorm.DB {
+	q = q.normalize()
+
+	if q.Author != nil {
+		db = db.Where("posts.author = ?", *q.Author)
+	}
+	if q.CreatedAfter != nil {
+		db = db.Where("posts.created_at > ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		db = db.Where("posts.created_at < ?", *q.CreatedBefore)
+	}
+	if q.CommentsEnabled != nil {
+		db = db.Where("posts.comments_enabled = ?", *q.CommentsEnabled)
+	}
+	if q.HasComments != nil {
+		exists := "EXISTS (SELECT 1 FROM comments WHERE comments.post_id = posts.id)"
+		if !*q.HasComments {
+			exists = "NOT " + exists
+		}
+		db = db.Where(exists)
+	}
+
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+	column := "posts." + string(q.SortBy)
+	if q.SortBy == SortCommentCount {
+		column = commentCountExpr
+	}
+	// ? posts.id breaks ties so the order is stable between requests
+	return db.Order(column + " " + direction).Order("posts.id " + direction)
+}
+
+// CommentSort enumerates the orderings supported when listing comments.
+type CommentSort string
+
+const (
+	SortOldest CommentSort = "oldest"
+	SortNewest CommentSort = "newest"
+	SortTop    CommentSort = "top"
+	SortActive CommentSort = "active"
+)
+
+// CommentPage describes ordering and cursor pagination for a list of comments.
+type CommentPage struct {
+	Sort  CommentSort
+	First *int
+	After *CommentCursor // Position of the last comment of the previous page
+}
+
+// CommentCursor is the position of a comment in one ordering: its sort key and ID.
+// Pages continue after the position rather than after the comment itself, so a
+// boundary stays put when scores or activity change between requests.
+type CommentCursor struct {
+	Sort CommentSort // ? Empty for a bare comment ID, which is looked up in the list
+	Key  int64       // ? Score for TOP, Unix nanoseconds of the ordering time otherwise
+	ID   uint
+}
+
+// ParseCommentCursor parses a cursor made by CommentCursor.String. A bare comment ID
+// is accepted as well and points right after that comment.
+func ParseCommentCursor(raw string) (*CommentCursor, error) {
+	if id, err := strconv.ParseUint(raw, 10, 64); err == nil {
+		return &CommentCursor{ID: uint(id)}, nil
+	}
+
+	parts := strings.Split(raw, ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+	order := CommentSort(parts[0])
+	switch order {
+	case SortOldest, SortNewest, SortTop, SortActive:
+	default:
+		return nil, ErrInvalidCursor
+	}
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &CommentCursor{Sort: order, Key: key, ID: uint(id)}, nil
+}
+
+// String encodes the cursor for clients.
+func (c CommentCursor) String() string {
+	if c.Sort == "" {
+		return strconv.FormatUint(uint64(c.ID), 10)
+	}
+	return fmt.Sprintf("%s:%d:%d", c.Sort, c.Key, c.ID)
+}
+
+// sort returns the ordering of the page, OLDEST when none was given.
+func (p CommentPage) sort() CommentSort {
+	switch p.Sort {
+	case SortNewest, SortTop, SortActive:
+		return p.Sort
+	default:
+		return SortOldest
+	}
+}
+
+// CursorOf returns the position of the comment in the ordering of the page.
+func (p CommentPage) CursorOf(comment *models.Comment) CommentCursor {
+	cursor := CommentCursor{Sort: p.sort(), ID: comment.ID}
+	switch cursor.Sort {
+	case SortTop:
+		cursor.Key = int64(comment.Score)
+	case SortActive:
+		cursor.Key = comment.LastActivityAt.UnixNano()
+	default:
+		cursor.Key = comment.CreatedAt.UnixNano()
+	}
+	return cursor
+}
+
+// less reports whether a goes before b. Ties are broken by ID so the order is total
+// and a page boundary always falls at the same place.
+func (p CommentPage) less(a, b *models.Comment) bool {
+	switch p.sort() {
+	case SortNewest:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	case SortTop:
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.ID < b.ID
+	case SortActive:
+		if !a.LastActivityAt.Equal(b.LastActivityAt) {
+			return a.LastActivityAt.After(b.LastActivityAt)
+		}
+		return a.ID > b.ID
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+}
+
+// apply sorts the comments and cuts out the requested page.
+func (p CommentPage) apply(comments []models.Comment) ([]models.Comment, error) {
+	if p.First != nil && *p.First < 0 {
+		return nil, ErrInvalidPageSize
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return p.less(&comments[i], &comments[j])
+	})
+
+	if p.After != nil {
+		start, err := p.start(comments)
+		if err != nil {
+			return nil, err
+		}
+		comments = comments[start:]
+	}
+
+	if p.First != nil && *p.First < len(comments) {
+		comments = comments[:*p.First]
+	}
+	return comments, nil
+}
+
+// start returns the index of the first sorted comment after the cursor of the page.
+func (p CommentPage) start(comments []models.Comment) (int, error) {
+	if p.After.Sort == "" {
+		for i := range comments {
+			if comments[i].ID == p.After.ID {
+				return i + 1, nil
+			}
+		}
+		return 0, ErrInvalidCursor
+	}
+	if p.After.Sort != p.sort() {
+		return 0, ErrCursorOrder
+	}
+
+	// ? The comment the cursor was made from may have moved or gone, only its old position counts
+	last := &models.Comment{
+		ID:             p.After.ID,
+		Score:          int(p.After.Key),
+		CreatedAt:      time.Unix(0, p.After.Key),
+		LastActivityAt: time.Unix(0, p.After.Key),
+	}
+	return sort.Search(len(comments), func(i int) bool {
+		return p.less(last, &comments[i])
+	}), nil
+}
@@ -0,0 +1,35 @@
+package database
+
+import "testing"
+
+func TestPostQueryCacheKey(t *testing.T) {
+	author := func(value string) *string { return &value }
+	enabled := true
+
+	tests := []struct {
+		name string
+		a, b PostQuery
+	}{
+		{
+			name: "separator in author",
+			a:    PostQuery{Author: author("x|enabled=true")},
+			b:    PostQuery{Author: author("x"), CommentsEnabled: &enabled},
+		},
+		{
+			name: "quote in author",
+			a:    PostQuery{Author: author(`x"|enabled=true`)},
+			b:    PostQuery{Author: author(`x"`), CommentsEnabled: &enabled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := tt.a.cacheKey(); key == tt.b.cacheKey() {
+				t.Errorf("different queries share the cache key %q", key)
+			}
+		})
+	}
+
+	if key := (PostQuery{Author: author(" x ")}).cacheKey(); key != (PostQuery{Author: author("x")}).cacheKey() {
+		t.Errorf("equal queries got different cache keys, %q", key)
+	}
+}
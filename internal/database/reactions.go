@@ -0,0 +1,186 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+var ErrInvalidReaction = errors.New("unknown reaction kind")
+
+// voteScores holds the score contribution of vote reactions.
+var voteScores = map[models.ReactionKind]int{
+	models.ReactionUpvote:   1,
+	models.ReactionDownvote: -1,
+}
+
+// opposingVotes maps each vote to the vote it replaces.
+var opposingVotes = map[models.ReactionKind]models.ReactionKind{
+	models.ReactionUpvote:   models.ReactionDownvote,
+	models.ReactionDownvote: models.ReactionUpvote,
+}
+
+// React adds a user's reaction to a post or a comment. A vote replaces the
+// user's opposite vote. Counters and score are updated in the same transaction.
+func (s *PostService) React(target models.TargetType, targetID uint, user string, kind models.ReactionKind) (*models.ReactionSummary, error) {
+	if err := s.validateReaction(user, kind); err != nil {
+		return nil, err
+	}
+
+	var summary *models.ReactionSummary
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		postID, err := reactionPostID(tx, target, targetID)
+		if err != nil {
+			return err
+		}
+
+		if opposite, ok := opposingVotes[kind]; ok {
+			if err := removeReaction(tx, target, targetID, user, opposite); err != nil {
+				return err
+			}
+		}
+
+		reaction := models.Reaction{TargetType: target, TargetID: targetID, User: user, Kind: kind}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := adjustReactionCount(tx, target, targetID, kind, 1); err != nil {
+				return err
+			}
+		}
+		summary, event, err = reactionsChanged(tx, target, targetID, postID)
+		return err
+	})
+	if err != nil {
+		slog.Error("error adding reaction", "target_type", target, "target_id", targetID, "kind", kind, "error", err)
+		return nil, err
+	}
+
+	s.relayOutbox(event)
+	return summary, nil
+}
+
+// Unreact removes a user's reaction from a post or a comment.
+func (s *PostService) Unreact(target models.TargetType, targetID uint, user string, kind models.ReactionKind) (*models.ReactionSummary, error) {
+	if err := s.validateReaction(user, kind); err != nil {
+		return nil, err
+	}
+
+	var summary *models.ReactionSummary
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		postID, err := reactionPostID(tx, target, targetID)
+		if err != nil {
+			return err
+		}
+		if err := removeReaction(tx, target, targetID, user, kind); err != nil {
+			return err
+		}
+		summary, event, err = reactionsChanged(tx, target, targetID, postID)
+		return err
+	})
+	if err != nil {
+		slog.Error("error removing reaction", "target_type", target, "target_id", targetID, "kind", kind, "error", err)
+		return nil, err
+	}
+
+	s.relayOutbox(event)
+	return summary, nil
+}
+
+// GetReactionCounts returns the non-zero reaction counters of a target.
+func (s *PostService) GetReactionCounts(target models.TargetType, targetID uint) ([]models.ReactionCount, error) {
+	var counts []models.ReactionCount
+	err := s.DB.Where("target_type = ? AND target_id = ? AND count > 0", target, targetID).
+		Order("kind").Find(&counts).Error
+	if err != nil {
+		slog.Error("error fetching reaction counts", "target_type", target, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+// reactionsChanged reads the new score and counters of a target and records them
+// in the outbox for the subscribers of the post.
+func reactionsChanged(tx *gorm.DB, target models.TargetType, targetID, postID uint) (*models.ReactionSummary, *models.OutboxEvent, error) {
+	summary := &models.ReactionSummary{TargetType: target, TargetID: targetID, PostID: postID}
+	if err := tx.Table(reactionTable(target)).Select("score").Where("id = ?", targetID).Scan(&summary.Score).Error; err != nil {
+		return nil, nil, err
+	}
+	err := tx.Where("target_type = ? AND target_id = ? AND count > 0", target, targetID).
+		Order("kind").Find(&summary.Counts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event, err := addOutbox(tx, models.OutboxReactionsChanged, postID, outboxPayload{Reactions: summary})
+	if err != nil {
+		return nil, nil, err
+	}
+	return summary, event, nil
+}
+
+// removeReaction deletes a reaction and decrements its counter if it existed.
+func removeReaction(tx *gorm.DB, target models.TargetType, targetID uint, user string, kind models.ReactionKind) error {
+	result := tx.Where("target_type = ? AND target_id = ? AND \"user\" = ? AND kind = ?", target, targetID, user, kind).
+		Delete(&models.Reaction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return adjustReactionCount(tx, target, targetID, kind, -1)
+}
+
+// adjustReactionCount changes the counter of a reaction kind and the target's score.
+func adjustReactionCount(tx *gorm.DB, target models.TargetType, targetID uint, kind models.ReactionKind, delta int) error {
+	count := models.ReactionCount{TargetType: target, TargetID: targetID, Kind: kind, Count: delta}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("reaction_counts.count + ?", delta)}),
+	}).Create(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if score, ok := voteScores[kind]; ok {
+		return tx.Table(reactionTable(target)).Where("id = ?", targetID).
+			UpdateColumn("score", gorm.Expr("score + ?", score*delta)).Error
+	}
+	return nil
+}
+
+// reactionPostID verifies the target exists and returns the post it belongs to.
+func reactionPostID(tx *gorm.DB, target models.TargetType, targetID uint) (uint, error) {
+	switch target {
+	case models.TargetPost:
+		var post models.Post
+		if err := tx.Select("id").First(&post, targetID).Error; err != nil {
+			return 0, err
+		}
+		return post.ID, nil
+	case models.TargetComment:
+		var comment models.Comment
+		if err := tx.Select("id", "post_id").First(&comment, targetID).Error; err != nil {
+			return 0, err
+		}
+		return comment.PostID, nil
+	default:
+		return 0, ErrInvalidReaction
+	}
+}
+
+// reactionTable returns the table holding the score of a target.
+func reactionTable(target models.TargetType) string {
+	if target == models.TargetComment {
+		return "comments"
+	}
+	return "posts"
+}
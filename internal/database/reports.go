@@ -0,0 +1,129 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+// AutoModerator is recorded as the moderator of decisions made without one.
+const AutoModerator = "system"
+
+var ErrInvalidReportReason = errors.New("unknown report reason")
+
+// Report flags a post or comment for moderators. Each reader counts once per target
+// until a moderator decides on it. Published content reaching ReportThreshold open
+// reports is held for review. The X-User handle is chosen by the client, so towards
+// the threshold reports only count once per client IP.
+func (s *PostService) Report(target models.TargetType, targetID uint, reporter, reporterIP string, reason models.ReportReason, note *string) error {
+	if err := s.validateReport(reporter, reason, note); err != nil {
+		return err
+	}
+
+	var open int64
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := reactionPostID(tx, target, targetID); err != nil {
+			return err
+		}
+		if target == models.TargetComment {
+			var deleted bool
+			if err := tx.Model(&models.Comment{}).Select("is_deleted").Where("id = ?", targetID).Scan(&deleted).Error; err != nil {
+				return err
+			}
+			if deleted {
+				return ErrCommentDeleted
+			}
+		}
+
+		report := models.Report{TargetType: target, TargetID: targetID, Reporter: reporter, Reason: reason, Note: note}
+		if reporterIP != "" {
+			report.ReporterIP = &reporterIP
+		}
+		// ? A reader may report again once their previous report was resolved
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}, {Name: "reporter"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reporter_ip", "reason", "note", "resolved", "created_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "reports.resolved"}}},
+		}).Create(&report).Error
+		if err != nil {
+			return err
+		}
+
+		// ? Reports stored without an IP count per reader
+		return tx.Model(&models.Report{}).
+			Select("COUNT(DISTINCT COALESCE(reporter_ip, reporter))").
+			Where("target_type = ? AND target_id = ? AND NOT resolved", target, targetID).
+			Scan(&open).Error
+	})
+	if err != nil {
+		slog.Error("error reporting content", "target_type", target, "target_id", targetID, "error", err)
+		return err
+	}
+
+	slog.Info("content reported", "target_type", target, "target_id", targetID, "reason", reason, "open_reporters", open)
+	if s.cfg.ReportThreshold > 0 && open >= int64(s.cfg.ReportThreshold) {
+		return s.holdReported(target, targetID, open)
+	}
+	return nil
+}
+
+// holdReported sends published content that crossed the report threshold back to review.
+func (s *PostService) holdReported(target models.TargetType, targetID uint, open int64) error {
+	var status models.ContentStatus
+	if err := s.DB.Table(reactionTable(target)).Select("status").Where("id = ?", targetID).Scan(&status).Error; err != nil {
+		return err
+	}
+	if status != models.StatusPublished {
+		return nil
+	}
+
+	reason := fmt.Sprintf("reported %d times", open)
+	var err error
+	if target == models.TargetPost {
+		_, err = s.changePostStatus(targetID, models.StatusPending, AutoModerator, &reason, false)
+	} else {
+		_, err = s.changeCommentStatus(targetID, models.StatusPending, AutoModerator, &reason, false)
+	}
+	return err
+}
+
+// resolveReports closes the open reports about a target once a moderator decided on it.
+func resolveReports(tx *gorm.DB, target models.TargetType, targetID uint) error {
+	return tx.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND NOT resolved", target, targetID).
+		Update("resolved", true).Error
+}
+
+// GetMostReported returns the targets with the most open reports, optionally of one type.
+func (s *PostService) GetMostReported(target *models.TargetType, limit int) ([]models.ReportedItem, error) {
+	query := s.DB.Model(&models.Report{}).
+		Select("target_type, target_id, COUNT(*) AS report_count, MAX(created_at) AS last_reported_at").
+		Where("NOT resolved")
+	if target != nil {
+		query = query.Where("target_type = ?", *target)
+	}
+
+	var items []models.ReportedItem
+	err := query.Group("target_type, target_id").
+		Order("report_count DESC").Order("last_reported_at DESC").
+		Limit(limit).Scan(&items).Error
+	if err != nil {
+		slog.Error("error fetching most reported content", "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetCommentByID retrieves a single comment by ID.
+func (s *PostService) GetCommentByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.DB.First(&comment, id).Error; err != nil {
+		slog.Error("error fetching comment", "comment_id", id, "error", err)
+		return nil, err
+	}
+	return &comment, nil
+}
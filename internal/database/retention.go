@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+var (
+	ErrPostNotDeleted = errors.New("post is not deleted")
+	ErrRestoreExpired = errors.New("post can no longer be restored")
+)
+
+// RestorePost brings back a soft-deleted post within the retention window.
+// Only admins, the post's author and the user who deleted the post may restore it.
+func (s *PostService) RestorePost(id uint, viewer auth.Viewer) (*models.Post, error) {
+	var post models.Post
+	if err := s.DB.Unscoped().First(&post, id).Error; err != nil {
+		slog.Error("error fetching post to restore", "post_id", id, "error", err)
+		return nil, err
+	}
+
+	if !post.DeletedAt.Valid {
+		return nil, ErrPostNotDeleted
+	}
+	deleter := post.DeletedBy != nil && *post.DeletedBy == viewer.User
+	if viewer.Role < auth.RoleAdmin && (viewer.User == "" || (viewer.User != post.Author && !deleter)) {
+		return nil, auth.ErrForbidden
+	}
+	if time.Since(post.DeletedAt.Time) > s.cfg.PostRetention {
+		return nil, ErrRestoreExpired
+	}
+
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&post).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		post.DeletedAt = gorm.DeletedAt{}
+		post.DeletedBy = nil
+		event, err = addOutbox(tx, models.OutboxPostRestored, post.ID, outboxPayload{Post: &post})
+		return err
+	})
+	if err != nil {
+		slog.Error("error restoring post", "post_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("post restored", "post_id", id, "user", viewer.User)
+	s.relayOutbox(event)
+
+	return &post, nil
+}
+
+// StartPurgeJob periodically removes posts deleted longer than the retention window ago.
+// It stops when ctx is done.
+func (s *PostService) StartPurgeJob(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PurgeInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.PurgeDeletedPosts(); err != nil {
+					slog.Error("error purging deleted posts", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// PurgeDeletedPosts permanently removes expired soft-deleted posts together with
+// their comments, reactions, revisions, moderation actions and reports.
+func (s *PostService) PurgeDeletedPosts() error {
+	cutoff := time.Now().Add(-s.cfg.PostRetention)
+
+	var postIDs []uint
+	if err := s.DB.Unscoped().Model(&models.Post{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var commentIDs []uint
+		if err := tx.Model(&models.Comment{}).Where("post_id IN ?", postIDs).Pluck("id", &commentIDs).Error; err != nil {
+			return err
+		}
+
+		targets := map[models.TargetType][]uint{
+			models.TargetPost:    postIDs,
+			models.TargetComment: commentIDs,
+		}
+		for target, ids := range targets {
+			if len(ids) == 0 {
+				continue
+			}
+			for _, model := range []interface{}{&models.Reaction{}, &models.ReactionCount{}, &models.Revision{}, &models.ModerationAction{}, &models.Report{}, &models.Mention{}} {
+				if err := tx.Where("target_type = ? AND target_id IN ?", target, ids).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Where("post_id IN ?", postIDs).Delete(&models.Ban{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", postIDs).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", postIDs).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Post{}, postIDs).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range postIDs {
+		s.clearCache(fmt.Sprintf("comments:%d", id))
+	}
+	s.clearCache("posts*")
+	slog.Info("purged deleted posts", "count", len(postIDs))
+
+	return nil
+}
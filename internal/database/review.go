@@ -0,0 +1,261 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/moderation"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+var ErrInvalidStatus = errors.New("content cannot be moved to this status")
+
+// statusFor returns the status of new content given its moderation decision.
+func statusFor(decision moderation.Decision) models.ContentStatus {
+	if decision.Verdict == moderation.VerdictHoldForReview {
+		return models.StatusPending
+	}
+	return models.StatusPublished
+}
+
+// editedStatus returns the status of edited content. Published content held by
+// moderation goes back to review; any other status is left to moderators.
+func editedStatus(current models.ContentStatus, decision moderation.Decision) models.ContentStatus {
+	if decision.Verdict == moderation.VerdictHoldForReview && current == models.StatusPublished {
+		return models.StatusPending
+	}
+	return current
+}
+
+// CanView reports whether the viewer may see content with the given author and status.
+// Content that is not published is visible only to its author and to moderators.
+func CanView(viewer auth.Viewer, author string, status models.ContentStatus) bool {
+	// ? Entries cached before statuses existed have none and were all published
+	if status == models.StatusPublished || status == "" {
+		return true
+	}
+	return viewer.Role >= auth.RoleModerator || (viewer.User != "" && viewer.User == author)
+}
+
+// CanEdit reports whether the viewer may change or restore content written by author.
+// Authors may edit their own content and moderators any content.
+func CanEdit(viewer auth.Viewer, author string) bool {
+	return viewer.Role >= auth.RoleModerator || (viewer.User != "" && viewer.User == author)
+}
+
+// visibleComments drops the comments the viewer may not see together with their replies.
+func visibleComments(comments []models.Comment, viewer auth.Viewer) []models.Comment {
+	byID := make(map[uint]*models.Comment, len(comments))
+	for i := range comments {
+		byID[comments[i].ID] = &comments[i]
+	}
+
+	visible := make(map[uint]bool, len(comments))
+	var isVisible func(comment *models.Comment) bool
+	isVisible = func(comment *models.Comment) bool {
+		if v, ok := visible[comment.ID]; ok {
+			return v
+		}
+		v := CanView(viewer, comment.Author, comment.Status)
+		if v && comment.CommentID != nil {
+			if parent, ok := byID[*comment.CommentID]; ok {
+				v = isVisible(parent)
+			}
+		}
+		visible[comment.ID] = v
+		return v
+	}
+
+	kept := make([]models.Comment, 0, len(comments))
+	for i := range comments {
+		if isVisible(&comments[i]) {
+			kept = append(kept, comments[i])
+		}
+	}
+	return kept
+}
+
+// publishPostStatus notifies subscribers about a post whose status may have changed.
+// Subscribers only ever see published posts, so a post entering or leaving the
+// published state looks like it was created or deleted. Mentioned users are
+// notified once the post is published.
+func (s *PostService) publishPostStatus(eventID string, post *models.Post, previous models.ContentStatus) error {
+	published := post.Status == models.StatusPublished
+	wasPublished := previous == models.StatusPublished
+	if published {
+		if err := s.notifyPost(eventID, post); err != nil {
+			return err
+		}
+	}
+	switch {
+	case published && !wasPublished:
+		return s.publishPostEvent(eventID, post.ID, &models.PostCreated{EventID: eventID, Post: post})
+	case !published && wasPublished:
+		return s.publishPostEvent(eventID, post.ID, &models.PostDeleted{EventID: eventID, Post: post})
+	case published:
+		return s.publishPostEvent(eventID, post.ID, &models.PostUpdated{EventID: eventID, Post: post})
+	}
+	return nil
+}
+
+// publishCommentStatus notifies subscribers about a comment whose status may have changed,
+// the same way publishPostStatus does for posts. Replies also notify the parent comment's author.
+func (s *PostService) publishCommentStatus(eventID string, comment *models.Comment, previous models.ContentStatus) error {
+	published := comment.Status == models.StatusPublished
+	wasPublished := previous == models.StatusPublished
+	if published {
+		if err := s.notifyComment(eventID, comment); err != nil {
+			return err
+		}
+	}
+	switch {
+	case published && !wasPublished:
+		return s.publishCommentEvent(eventID, comment.PostID, func(sequence int64) models.CommentEvent {
+			return &models.CommentAdded{Sequence: sequence, Comment: comment}
+		})
+	case !published && wasPublished:
+		return s.publishCommentEvent(eventID, comment.PostID, func(sequence int64) models.CommentEvent {
+			return &models.CommentDeleted{Sequence: sequence, Comment: comment}
+		})
+	case published:
+		return s.publishCommentEvent(eventID, comment.PostID, func(sequence int64) models.CommentEvent {
+			return &models.CommentUpdated{Sequence: sequence, Comment: comment}
+		})
+	}
+	return nil
+}
+
+// SetPostStatus applies a moderator decision to a post, records it and resolves the open reports about it.
+func (s *PostService) SetPostStatus(id uint, status models.ContentStatus, moderator string, reason *string) (*models.Post, error) {
+	if status == models.StatusPending {
+		return nil, ErrInvalidStatus
+	}
+	return s.changePostStatus(id, status, moderator, reason, true)
+}
+
+// changePostStatus moves a post to a status and records who did it and why.
+func (s *PostService) changePostStatus(id uint, status models.ContentStatus, moderator string, reason *string, resolve bool) (*models.Post, error) {
+	var post models.Post
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&post, id).Error; err != nil {
+			return err
+		}
+		previous := post.Status
+		post.Status = status
+		if err := tx.Model(&post).Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := recordModeration(tx, models.TargetPost, id, status, moderator, reason); err != nil {
+			return err
+		}
+		if resolve {
+			if err := resolveReports(tx, models.TargetPost, id); err != nil {
+				return err
+			}
+		}
+		var err error
+		event, err = addOutbox(tx, models.OutboxPostChanged, id, outboxPayload{Post: &post, Previous: previous})
+		return err
+	})
+	if err != nil {
+		slog.Error("error moderating post", "post_id", id, "status", status, "error", err)
+		return nil, err
+	}
+
+	slog.Info("post moderated", "post_id", id, "status", status, "moderator", moderator)
+	s.relayOutbox(event)
+
+	return &post, nil
+}
+
+// SetCommentStatus applies a moderator decision to a comment, records it and resolves the open reports about it.
+func (s *PostService) SetCommentStatus(id uint, status models.ContentStatus, moderator string, reason *string) (*models.Comment, error) {
+	if status == models.StatusPending {
+		return nil, ErrInvalidStatus
+	}
+	return s.changeCommentStatus(id, status, moderator, reason, true)
+}
+
+// changeCommentStatus moves a comment to a status and records who did it and why.
+func (s *PostService) changeCommentStatus(id uint, status models.ContentStatus, moderator string, reason *string, resolve bool) (*models.Comment, error) {
+	var comment models.Comment
+	var event *models.OutboxEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&comment, id).Error; err != nil {
+			return err
+		}
+		if comment.IsDeleted {
+			return ErrCommentDeleted
+		}
+		previous := comment.Status
+		comment.Status = status
+		if err := tx.Model(&comment).Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := recordModeration(tx, models.TargetComment, id, status, moderator, reason); err != nil {
+			return err
+		}
+		if resolve {
+			if err := resolveReports(tx, models.TargetComment, id); err != nil {
+				return err
+			}
+		}
+		var err error
+		event, err = addOutbox(tx, models.OutboxCommentChanged, comment.PostID, outboxPayload{Comment: &comment, Previous: previous})
+		return err
+	})
+	if err != nil {
+		slog.Error("error moderating comment", "comment_id", id, "status", status, "error", err)
+		return nil, err
+	}
+
+	slog.Info("comment moderated", "comment_id", id, "status", status, "moderator", moderator)
+	s.relayOutbox(event)
+
+	return &comment, nil
+}
+
+// recordModeration stores the audit record of a moderator decision.
+func recordModeration(tx *gorm.DB, target models.TargetType, id uint, status models.ContentStatus, moderator string, reason *string) error {
+	return tx.Create(&models.ModerationAction{
+		TargetType: target,
+		TargetID:   id,
+		Status:     status,
+		Moderator:  moderator,
+		Reason:     reason,
+	}).Error
+}
+
+// GetModerationActions returns the moderator decisions about a post or comment, newest first.
+func (s *PostService) GetModerationActions(target models.TargetType, targetID uint) ([]models.ModerationAction, error) {
+	var actions []models.ModerationAction
+	err := s.DB.Where("target_type = ? AND target_id = ?", target, targetID).
+		Order("created_at DESC").Order("id DESC").Find(&actions).Error
+	if err != nil {
+		slog.Error("error fetching moderation actions", "target_type", target, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	return actions, nil
+}
+
+// GetModerationQueue returns up to limit posts and comments waiting for review, oldest first.
+func (s *PostService) GetModerationQueue(limit int) ([]models.Post, []models.Comment, error) {
+	var posts []models.Post
+	if err := s.DB.Where("status = ?", models.StatusPending).
+		Order("created_at").Order("id").Limit(limit).Find(&posts).Error; err != nil {
+		slog.Error("error fetching pending posts", "error", err)
+		return nil, nil, err
+	}
+
+	var comments []models.Comment
+	if err := s.DB.Where("status = ? AND NOT is_deleted", models.StatusPending).
+		Order("created_at").Order("id").Limit(limit).Find(&comments).Error; err != nil {
+		slog.Error("error fetching pending comments", "error", err)
+		return nil, nil, err
+	}
+
+	return posts, comments, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"log/slog"
+)
+
+var ErrRevisionTarget = errors.New("revision belongs to a different kind of entity")
+
+// GetRevisions returns the previous versions of a post or a comment, newest first.
+func (s *PostService) GetRevisions(target models.TargetType, targetID uint) ([]models.Revision, error) {
+	var revisions []models.Revision
+	err := s.DB.Where("target_type = ? AND target_id = ?", target, targetID).
+		Order("created_at DESC").Order("id DESC").Find(&revisions).Error
+	if err != nil {
+		slog.Error("error fetching revisions", "target_type", target, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// RestorePostRevision brings back the title and content stored in a post revision.
+// The replaced version is kept as a new revision.
+func (s *PostService) RestorePostRevision(revisionID uint, viewer auth.Viewer) (*models.Post, error) {
+	revision, err := s.getRevision(revisionID, models.TargetPost)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.GetPostByID(revision.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Title != nil {
+		post.Title = *revision.Title
+	}
+	post.Content = revision.Content
+
+	if err := s.UpdatePost(post, viewer, nil); err != nil {
+		return nil, err
+	}
+	slog.Info("post revision restored", "post_id", post.ID, "revision_id", revisionID, "editor", viewer.User)
+	return post, nil
+}
+
+// RestoreCommentRevision brings back the content stored in a comment revision.
+// The replaced version is kept as a new revision.
+func (s *PostService) RestoreCommentRevision(revisionID uint, viewer auth.Viewer) (*models.Comment, error) {
+	revision, err := s.getRevision(revisionID, models.TargetComment)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.UpdateComment(revision.TargetID, revision.Content, viewer, nil)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("comment revision restored", "comment_id", comment.ID, "revision_id", revisionID, "editor", viewer.User)
+	return comment, nil
+}
+
+// getRevision loads a revision and checks that it belongs to the expected kind of entity.
+func (s *PostService) getRevision(id uint, target models.TargetType) (*models.Revision, error) {
+	var revision models.Revision
+	if err := s.DB.First(&revision, id).Error; err != nil {
+		slog.Error("error fetching revision", "revision_id", id, "error", err)
+		return nil, err
+	}
+	if revision.TargetType != target {
+		return nil, ErrRevisionTarget
+	}
+	return &revision, nil
+}
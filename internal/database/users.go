@@ -0,0 +1,160 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+const DefaultUserPageSize = 20 // ? Posts and comments per page of a profile
+
+// GetUserByHandle retrieves a profile by handle, using cache if available.
+func (s *PostService) GetUserByHandle(handle string) (*models.User, error) {
+	var user models.User
+	cacheKey := fmt.Sprintf("user:%s", handle)
+
+	if err := s.getFromCache(cacheKey, &user); err == nil {
+		return &user, nil
+	}
+
+	if err := s.DB.Where("handle = ?", handle).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Error("error fetching user", "handle", handle, "error", err)
+		}
+		return nil, err
+	}
+	s.setToCache(cacheKey, user)
+	return &user, nil
+}
+
+// GetAuthors returns the profiles behind author handles with a single query. Authors
+// without a profile get one made up from the handle; profiles are only created when
+// their author writes a post or comment.
+func (s *PostService) GetAuthors(handles []string) (map[string]*models.User, error) {
+	var users []models.User
+	if err := s.DB.Where("handle IN ?", handles).Find(&users).Error; err != nil {
+		slog.Error("error fetching authors", "handles", len(handles), "error", err)
+		return nil, err
+	}
+
+	authors := make(map[string]*models.User, len(handles))
+	for i := range users {
+		authors[users[i].Handle] = &users[i]
+	}
+	for _, handle := range handles {
+		if _, ok := authors[handle]; !ok {
+			authors[handle] = &models.User{Handle: handle, DisplayName: handle}
+		}
+	}
+	return authors, nil
+}
+
+// ensureUser creates a profile named after the handle unless one exists.
+func (s *PostService) ensureUser(handle string) error {
+	user := models.User{Handle: handle, DisplayName: handle}
+	err := s.DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "handle"}}, DoNothing: true}).Create(&user).Error
+	if err != nil {
+		slog.Error("error creating user", "handle", handle, "error", err)
+	}
+	return err
+}
+
+// UpdateProfile changes the display name, bio and avatar of a user. Nil fields are left unchanged.
+func (s *PostService) UpdateProfile(handle string, displayName, bio, avatarURL *string) (*models.User, error) {
+	if err := s.ensureUser(handle); err != nil {
+		return nil, err
+	}
+	user, err := s.GetUserByHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+	if displayName != nil {
+		user.DisplayName = *displayName
+	}
+	if bio != nil {
+		user.Bio = bio
+	}
+	if avatarURL != nil {
+		user.AvatarURL = avatarURL
+	}
+	if err := s.validateProfile(user); err != nil {
+		return nil, err
+	}
+
+	var event *models.OutboxEvent
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"display_name": user.DisplayName,
+			"bio":          user.Bio,
+			"avatar_url":   user.AvatarURL,
+		}).Error
+		if err != nil {
+			return err
+		}
+		event, err = addOutbox(tx, models.OutboxProfileUpdated, 0, outboxPayload{User: user})
+		return err
+	})
+	if err != nil {
+		slog.Error("error updating profile", "handle", handle, "error", err)
+		return nil, err
+	}
+
+	s.relayOutbox(event)
+	return user, nil
+}
+
+// GetUserPosts returns one page of the posts of a user that the viewer may see, newest first.
+// The after cursor is the ID of the last post of the previous page.
+func (s *PostService) GetUserPosts(handle string, viewer auth.Viewer, first *int, after *uint) ([]models.Post, error) {
+	query, err := userPage(s.DB.Where("author = ?", handle), handle, viewer, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []models.Post
+	if err := query.Find(&posts).Error; err != nil {
+		slog.Error("error fetching user posts", "handle", handle, "error", err)
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetUserComments returns one page of the comments of a user that the viewer may see, newest first.
+// Deleted comments and comments on deleted posts are left out.
+func (s *PostService) GetUserComments(handle string, viewer auth.Viewer, first *int, after *uint) ([]models.Comment, error) {
+	query, err := userPage(s.DB.Where("author = ? AND NOT is_deleted", handle), handle, viewer, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	err = query.Where("post_id IN (?)", s.DB.Model(&models.Post{}).Select("id")).Find(&comments).Error
+	if err != nil {
+		slog.Error("error fetching user comments", "handle", handle, "error", err)
+		return nil, err
+	}
+	return comments, nil
+}
+
+// userPage limits a query of a user's content to one page the viewer may see.
+func userPage(db *gorm.DB, handle string, viewer auth.Viewer, first *int, after *uint) (*gorm.DB, error) {
+	limit := DefaultUserPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidPageSize
+		}
+		limit = *first
+	}
+	if !CanView(viewer, handle, models.StatusPending) {
+		db = db.Where("status = ?", models.StatusPublished)
+	}
+	if after != nil {
+		db = db.Where("id < ?", *after)
+	}
+	return db.Order("id DESC").Limit(limit), nil
+}
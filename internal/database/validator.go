@@ -2,40 +2,151 @@ package database
 
 import (
 	"errors"
+	"net/url"
+
 	"github.com/likimiad/ozon_fintech/internal/database/models"
 )
 
 var (
-	ErrEmptyTitle   = errors.New("title cannot be empty")
-	ErrEmptyContent = errors.New("content cannot be empty")
-	ErrContentLimit = errors.New("content exceeds maximum length of 2000 characters")
-	ErrEmptyAuthor  = errors.New("author cannot be empty")
+	ErrEmptyTitle      = errors.New("title cannot be empty")
+	ErrEmptyContent    = errors.New("content cannot be empty")
+	ErrContentLimit    = errors.New("content exceeds maximum length of 2000 characters")
+	ErrEmptyAuthor     = errors.New("author cannot be empty")
+	ErrAuthorNotViewer = errors.New("author must be the user sending the request")
+	ErrEmptyUser       = errors.New("user cannot be empty")
+	ErrNoteLimit       = errors.New("note exceeds maximum length of 1000 characters")
+
+	ErrEmptyDisplayName = errors.New("display name cannot be empty")
+	ErrDisplayNameLimit = errors.New("display name exceeds maximum length of 100 characters")
+	ErrBioLimit         = errors.New("bio exceeds maximum length of 500 characters")
+	ErrInvalidAvatarURL = errors.New("avatar URL must be an absolute http or https URL")
+
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+	ErrNoWebhookEvents   = errors.New("webhook must subscribe to at least one event")
 )
 
-// validatePost checks if the post has valid fields.
+// FieldError is a validation failure of a single field of a post or comment.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors returns every FieldError joined into err.
+func FieldErrors(err error) []*FieldError {
+	var fieldErrs []*FieldError
+	var fieldErr *FieldError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if errors.As(e, &fieldErr) {
+				fieldErrs = append(fieldErrs, fieldErr)
+			}
+		}
+	} else if errors.As(err, &fieldErr) {
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+	return fieldErrs
+}
+
+// validatePost checks if the post has valid fields and reports every invalid one.
 func (s *PostService) validatePost(post *models.Post) error {
+	var errs []error
 	if post.Title == "" {
-		return ErrEmptyTitle
+		errs = append(errs, &FieldError{Field: "title", Err: ErrEmptyTitle})
 	}
 	if post.Content == "" {
-		return ErrEmptyContent
+		errs = append(errs, &FieldError{Field: "content", Err: ErrEmptyContent})
 	}
 	if post.Author == "" {
-		return ErrEmptyAuthor
+		errs = append(errs, &FieldError{Field: "author", Err: ErrEmptyAuthor})
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// validateComment checks if the comment has valid fields.
+// validateComment checks if the comment has valid fields and reports every invalid one.
 func (s *PostService) validateComment(comment *models.Comment) error {
+	var errs []error
 	if comment.Content == "" {
-		return ErrEmptyContent
+		errs = append(errs, &FieldError{Field: "content", Err: ErrEmptyContent})
 	}
 	if len(comment.Content) > 2000 {
-		return ErrContentLimit
+		errs = append(errs, &FieldError{Field: "content", Err: ErrContentLimit})
 	}
 	if comment.Author == "" {
-		return ErrEmptyAuthor
+		errs = append(errs, &FieldError{Field: "author", Err: ErrEmptyAuthor})
+	}
+	return errors.Join(errs...)
+}
+
+// validateReaction checks if the reaction has a user and a known kind.
+func (s *PostService) validateReaction(user string, kind models.ReactionKind) error {
+	if user == "" {
+		return ErrEmptyUser
 	}
-	return nil
+	switch kind {
+	case models.ReactionUpvote, models.ReactionDownvote, models.ReactionLike, models.ReactionHeart,
+		models.ReactionLaugh, models.ReactionSurprised, models.ReactionSad, models.ReactionAngry:
+		return nil
+	default:
+		return ErrInvalidReaction
+	}
+}
+
+// validateReport checks if the report has a reporter, a known reason and a short enough note.
+func (s *PostService) validateReport(reporter string, reason models.ReportReason, note *string) error {
+	if reporter == "" {
+		return ErrEmptyUser
+	}
+	if note != nil && len(*note) > 1000 {
+		return ErrNoteLimit
+	}
+	switch reason {
+	case models.ReportSpam, models.ReportAbuse, models.ReportHarassment, models.ReportMisinformation, models.ReportOther:
+		return nil
+	default:
+		return ErrInvalidReportReason
+	}
+}
+
+// validateProfile checks if the profile has valid fields and reports every invalid one.
+func (s *PostService) validateProfile(user *models.User) error {
+	var errs []error
+	if user.DisplayName == "" {
+		errs = append(errs, &FieldError{Field: "displayName", Err: ErrEmptyDisplayName})
+	}
+	if len(user.DisplayName) > 100 {
+		errs = append(errs, &FieldError{Field: "displayName", Err: ErrDisplayNameLimit})
+	}
+	if user.Bio != nil && len(*user.Bio) > 500 {
+		errs = append(errs, &FieldError{Field: "bio", Err: ErrBioLimit})
+	}
+	if user.AvatarURL != nil && *user.AvatarURL != "" && !isHTTPURL(*user.AvatarURL) {
+		errs = append(errs, &FieldError{Field: "avatarUrl", Err: ErrInvalidAvatarURL})
+	}
+	return errors.Join(errs...)
+}
+
+// validateWebhook checks if the webhook has a valid URL and events and reports every invalid field.
+func (s *PostService) validateWebhook(hook *models.Webhook) error {
+	var errs []error
+	if !isHTTPURL(hook.URL) {
+		errs = append(errs, &FieldError{Field: "url", Err: ErrInvalidWebhookURL})
+	}
+	if len(hook.Events) == 0 {
+		errs = append(errs, &FieldError{Field: "events", Err: ErrNoWebhookEvents})
+	}
+	return errors.Join(errs...)
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL.
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
 }
@@ -0,0 +1,344 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/likimiad/ozon_fintech/internal/config"
+	"github.com/likimiad/ozon_fintech/internal/database/models"
+	"github.com/likimiad/ozon_fintech/internal/webhook"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+const DefaultDeliveryPageSize = 50
+
+var ErrDeliveryNotDead = errors.New("only dead deliveries can be retried")
+
+// CreateWebhook registers an endpoint for the given events. The returned webhook
+// carries its generated signing secret.
+func (s *PostService) CreateWebhook(url string, events []models.WebhookEvent, createdBy string) (*models.Webhook, error) {
+	hook := &models.Webhook{
+		URL:       url,
+		Events:    uniqueEvents(events),
+		Active:    true,
+		CreatedBy: createdBy,
+	}
+	if err := s.validateWebhook(hook); err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = "whsec_" + secret
+
+	if err := s.DB.Create(hook).Error; err != nil {
+		slog.Error("error creating webhook", "url", url, "error", err)
+		return nil, err
+	}
+	slog.Info("webhook created", "webhook_id", hook.ID, "url", url, "events", hook.Events, "created_by", createdBy)
+	return hook, nil
+}
+
+// UpdateWebhook changes the URL, events or active state of a webhook. Nil fields are left unchanged.
+func (s *PostService) UpdateWebhook(id uint, url *string, events []models.WebhookEvent, active *bool) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := s.DB.First(&hook, id).Error; err != nil {
+		return nil, err
+	}
+	if url != nil {
+		hook.URL = *url
+	}
+	if events != nil {
+		hook.Events = uniqueEvents(events)
+	}
+	if active != nil {
+		hook.Active = *active
+	}
+	if err := s.validateWebhook(&hook); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Save(&hook).Error; err != nil {
+		slog.Error("error updating webhook", "webhook_id", id, "error", err)
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// DeleteWebhook removes a webhook together with its deliveries.
+func (s *PostService) DeleteWebhook(id uint) error {
+	result := s.DB.Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		slog.Error("error deleting webhook", "webhook_id", id, "error", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetWebhooks returns every registered webhook.
+func (s *PostService) GetWebhooks() ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	if err := s.DB.Order("id").Find(&hooks).Error; err != nil {
+		slog.Error("error fetching webhooks", "error", err)
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetWebhookDeliveries returns one page of the delivery log, newest first, optionally
+// only of one webhook or status. The after cursor is the ID of the last delivery of the previous page.
+func (s *PostService) GetWebhookDeliveries(webhookID *uint, status *models.DeliveryStatus, first *int, after *uint) ([]models.WebhookDelivery, error) {
+	limit := DefaultDeliveryPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidPageSize
+		}
+		limit = *first
+	}
+
+	query := s.DB.Model(&models.WebhookDelivery{}).Preload("Webhook")
+	if webhookID != nil {
+		query = query.Where("webhook_id = ?", *webhookID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if after != nil {
+		query = query.Where("id < ?", *after)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		slog.Error("error fetching webhook deliveries", "error", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery sends a dead delivery again with a fresh set of attempts.
+func (s *PostService) RetryWebhookDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := s.DB.Preload("Webhook").First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	if delivery.Status != models.DeliveryDead {
+		return nil, ErrDeliveryNotDead
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	err := s.DB.Model(&delivery).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+	}).Error
+	if err != nil {
+		slog.Error("error retrying webhook delivery", "delivery_id", id, "error", err)
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// enqueueWebhooks adds a delivery of the event to the outbox of every active webhook subscribed to it.
+// The event ID identifies the event for receivers, so enqueueing it again adds nothing.
+func (s *PostService) enqueueWebhooks(eventID string, event models.WebhookEvent, data interface{}) error {
+	var hooks []models.Webhook
+	if err := s.DB.Where("active").Find(&hooks).Error; err != nil {
+		slog.Warn("failed to fetch webhooks", "event", event, "error", err)
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, hook := range hooks {
+		if hook.Events.Has(event) {
+			deliveries = append(deliveries, models.WebhookDelivery{WebhookID: hook.ID})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(webhook.Payload{ID: eventID, Event: event.Name(), CreatedAt: now, Data: data})
+	if err != nil {
+		slog.Warn("failed to encode webhook payload", "event", event, "error", err)
+		return err
+	}
+
+	for i := range deliveries {
+		deliveries[i].EventID = eventID
+		deliveries[i].Event = event
+		deliveries[i].Payload = string(payload)
+		deliveries[i].Status = models.DeliveryPending
+		deliveries[i].NextAttemptAt = now
+	}
+	if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		slog.Warn("failed to enqueue webhook deliveries", "event", event, "error", err)
+		return err
+	}
+	return nil
+}
+
+// enqueuePostWebhook enqueues the webhook deliveries of a post event.
+func (s *PostService) enqueuePostWebhook(eventID string, event models.PostEvent) error {
+	switch e := event.(type) {
+	case *models.PostCreated:
+		return s.enqueueWebhooks(eventID, models.WebhookPostCreated, e.Post)
+	case *models.PostUpdated:
+		return s.enqueueWebhooks(eventID, models.WebhookPostUpdated, e.Post)
+	case *models.PostDeleted:
+		return s.enqueueWebhooks(eventID, models.WebhookPostDeleted, e.Post)
+	}
+	return nil
+}
+
+// enqueueCommentWebhook enqueues the webhook deliveries of a comment event.
+func (s *PostService) enqueueCommentWebhook(eventID string, event models.CommentEvent) error {
+	switch e := event.(type) {
+	case *models.CommentAdded:
+		return s.enqueueWebhooks(eventID, models.WebhookCommentCreated, e.Comment)
+	case *models.CommentUpdated:
+		return s.enqueueWebhooks(eventID, models.WebhookCommentUpdated, e.Comment)
+	case *models.CommentDeleted:
+		comment := *e.Comment
+		// ? Content of deleted comments is kept for moderators only
+		if comment.IsDeleted {
+			comment.Content = ""
+		}
+		return s.enqueueWebhooks(eventID, models.WebhookCommentDeleted, &comment)
+	}
+	return nil
+}
+
+// StartWebhookWorker periodically sends the due deliveries of the outbox.
+// It stops when ctx is done.
+func (s *PostService) StartWebhookWorker(ctx context.Context, cfg config.WebhookConfig) {
+	sender := webhook.NewSender(cfg.Timeout)
+	policy := webhook.Policy{MaxAttempts: cfg.MaxAttempts, Base: cfg.BackoffBase, Max: cfg.BackoffMax}
+	// ? Claimed deliveries are skipped by other instances until every send of the batch timed out
+	lease := 2 * cfg.Timeout
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.deliverWebhooks(ctx, sender, policy, cfg.BatchSize, lease); err != nil {
+					slog.Error("error delivering webhooks", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// deliverWebhooks claims up to batch due deliveries and sends them concurrently.
+func (s *PostService) deliverWebhooks(ctx context.Context, sender *webhook.Sender, policy webhook.Policy, batch int, lease time.Duration) error {
+	deliveries, err := s.claimDeliveries(batch, lease)
+	if err != nil || len(deliveries) == 0 {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			status, err := sender.Send(ctx, delivery.Webhook.URL, delivery.Webhook.Secret, delivery.EventID, delivery.Event.Name(), []byte(delivery.Payload))
+			s.recordAttempt(delivery, status, err, policy)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// claimDeliveries locks the due deliveries of active webhooks and pushes their next
+// attempt past the lease, so concurrent workers do not send them twice.
+func (s *PostService) claimDeliveries(batch int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var ids []uint
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Where("webhook_id IN (?)", tx.Model(&models.Webhook{}).Select("id").Where("active")).
+			Order("next_attempt_at").Limit(batch).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := s.DB.Preload("Webhook").Where("id IN ?", ids).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// recordAttempt stores the outcome of sending a delivery and schedules its retry
+// with exponential backoff, or dead-letters it once it is out of attempts.
+func (s *PostService) recordAttempt(delivery *models.WebhookDelivery, status int, sendErr error, policy webhook.Policy) {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+	if status != 0 {
+		updates["response_status"] = status
+	}
+
+	if sendErr == nil {
+		updates["status"] = models.DeliverySucceeded
+		updates["delivered_at"] = now
+		updates["last_error"] = nil
+	} else if delay, ok := policy.Next(attempts); ok {
+		updates["next_attempt_at"] = now.Add(delay)
+		updates["last_error"] = sendErr.Error()
+	} else {
+		updates["status"] = models.DeliveryDead
+		updates["last_error"] = sendErr.Error()
+		slog.Warn("webhook delivery dead-lettered", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "attempts", attempts, "error", sendErr)
+	}
+
+	if err := s.DB.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		slog.Error("error recording webhook attempt", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// uniqueEvents drops repeated events, keeping the first occurrence of each.
+func uniqueEvents(events []models.WebhookEvent) models.WebhookEvents {
+	unique := make(models.WebhookEvents, 0, len(events))
+	for _, event := range events {
+		if !unique.Has(event) {
+			unique = append(unique, event)
+		}
+	}
+	return unique
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BannedWords rejects content containing any of the configured words or phrases,
+// compared after Unicode normalization.
+type BannedWords struct {
+	phrases []string
+}
+
+// NewBannedWords normalizes the banned list once.
+func NewBannedWords(list []string) BannedWords {
+	phrases := make([]string, 0, len(list))
+	for _, entry := range list {
+		if phrase := strings.Join(words(Normalize(entry)), " "); phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return BannedWords{phrases: phrases}
+}
+
+// Name implements Filter.
+func (BannedWords) Name() string {
+	return "banned_words"
+}
+
+// Check implements Filter.
+func (f BannedWords) Check(content Content) (Decision, error) {
+	fields := []struct{ name, text string }{{"title", content.Title}, {"content", content.Content}}
+	for _, field := range fields {
+		// ? Padding keeps phrases from matching inside longer words
+		text := " " + strings.Join(words(Normalize(field.text)), " ") + " "
+		for _, phrase := range f.phrases {
+			if strings.Contains(text, " "+phrase+" ") {
+				return Decision{Verdict: VerdictReject, Field: field.name, Reason: "contains banned words"}, nil
+			}
+		}
+	}
+	return Allow, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkLimit holds content with more links than allowed for review.
+type LinkLimit struct {
+	Max int
+}
+
+// Name implements Filter.
+func (LinkLimit) Name() string {
+	return "links"
+}
+
+// Check implements Filter.
+func (f LinkLimit) Check(content Content) (Decision, error) {
+	if count := len(linkPattern.FindAllStringIndex(content.Content, -1)); count > f.Max {
+		return Decision{
+			Verdict: VerdictHoldForReview,
+			Field:   "content",
+			Reason:  fmt.Sprintf("contains %d links, at most %d allowed", count, f.Max),
+		}, nil
+	}
+	return Allow, nil
+}
+
+// RepeatedCharacters holds content with long runs of one character, a common sign of spam.
+type RepeatedCharacters struct {
+	MaxRun int
+}
+
+// Name implements Filter.
+func (RepeatedCharacters) Name() string {
+	return "repeated_chars"
+}
+
+// Check implements Filter.
+func (f RepeatedCharacters) Check(content Content) (Decision, error) {
+	var last rune
+	run := 0
+	for _, r := range content.Content {
+		if r == last {
+			run++
+		} else {
+			last, run = r, 1
+		}
+		if run > f.MaxRun && r != ' ' {
+			return Decision{Verdict: VerdictHoldForReview, Field: "content", Reason: "contains repeated characters"}, nil
+		}
+	}
+	return Allow, nil
+}
+
+// RecentContent returns the content the author published since the given time,
+// leaving out the item with excludeID.
+type RecentContent func(kind Kind, author string, since time.Time, excludeID uint) ([]string, error)
+
+// DuplicateContent rejects content the author already published within the window.
+type DuplicateContent struct {
+	Recent RecentContent
+	Window time.Duration
+}
+
+// Name implements Filter.
+func (DuplicateContent) Name() string {
+	return "duplicates"
+}
+
+// Check implements Filter.
+func (f DuplicateContent) Check(content Content) (Decision, error) {
+	recent, err := f.Recent(content.Kind, content.Author, time.Now().Add(-f.Window), content.ID)
+	if err != nil {
+		return Allow, err
+	}
+	normalized := Normalize(content.Content)
+	for _, previous := range recent {
+		if Normalize(previous) == normalized {
+			return Decision{Verdict: VerdictReject, Field: "content", Reason: fmt.Sprintf("duplicates a recent %s", content.Kind)}, nil
+		}
+	}
+	return Allow, nil
+}
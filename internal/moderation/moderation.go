@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var ErrRejected = errors.New("content rejected by moderation")
+
+// Verdict is the outcome of moderating a piece of content.
+type Verdict string
+
+const (
+	VerdictAllow         Verdict = "ALLOW"
+	VerdictHoldForReview Verdict = "HOLD_FOR_REVIEW"
+	VerdictReject        Verdict = "REJECT"
+)
+
+// severity orders verdicts so the strictest one wins.
+func (v Verdict) severity() int {
+	switch v {
+	case VerdictReject:
+		return 2
+	case VerdictHoldForReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Kind tells whether the content is a post or a comment.
+type Kind string
+
+const (
+	KindPost    Kind = "post"
+	KindComment Kind = "comment"
+)
+
+// Content is a post or comment about to be created or edited.
+type Content struct {
+	Kind    Kind
+	ID      uint // ? Zero for content that does not exist yet
+	Author  string
+	Title   string // ? Empty for comments
+	Content string
+}
+
+// Decision is a verdict with the filter and field that caused it.
+type Decision struct {
+	Verdict Verdict
+	Filter  string
+	Field   string
+	Reason  string
+}
+
+// Allow is the decision for content no filter objects to.
+var Allow = Decision{Verdict: VerdictAllow}
+
+// Err returns the error reported to the author of rejected content.
+func (d Decision) Err() error {
+	return fmt.Errorf("%w: %s", ErrRejected, d.Reason)
+}
+
+// Filter inspects content and returns a decision about it.
+type Filter interface {
+	Name() string
+	Check(content Content) (Decision, error)
+}
+
+// Pipeline runs filters in order and keeps the strictest decision.
+type Pipeline struct {
+	filters []Filter
+}
+
+// NewPipeline creates a pipeline from the given filters.
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Moderate runs every filter until one rejects the content. A filter that fails
+// is skipped so that an outage of its backing store does not block posting.
+func (p *Pipeline) Moderate(content Content) Decision {
+	decision := Allow
+	for _, filter := range p.filters {
+		result, err := filter.Check(content)
+		if err != nil {
+			slog.Warn("moderation filter failed", "filter", filter.Name(), "error", err)
+			continue
+		}
+		if result.Verdict.severity() <= decision.Verdict.severity() {
+			continue
+		}
+		result.Filter = filter.Name()
+		decision = result
+		if decision.Verdict == VerdictReject {
+			break
+		}
+	}
+	if decision.Verdict != VerdictAllow {
+		slog.Info("content moderated", "kind", content.Kind, "author", content.Author,
+			"verdict", decision.Verdict, "filter", decision.Filter, "reason", decision.Reason)
+	}
+	return decision
+}
@@ -0,0 +1,29 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize folds text so that visually equivalent spellings compare equal:
+// compatibility characters such as fullwidth letters are mapped by NFKC,
+// accents are stripped, letters are lowercased and whitespace is collapsed.
+func Normalize(text string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFKC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = norm.NFKC.String(text)
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// words splits normalized text into letter and digit runs.
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
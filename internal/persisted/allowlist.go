@@ -0,0 +1,68 @@
+package persisted
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/mitchellh/mapstructure"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errOperationNotAllowedCode = "OPERATION_NOT_ALLOWED"
+
+// Allowlist resolves registered operations sent by hash and, in strict mode,
+// rejects every operation that is not in the manifest.
+type Allowlist struct {
+	Manifest Manifest
+	Strict   bool
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = Allowlist{}
+
+// ExtensionName returns the extension name.
+func (a Allowlist) ExtensionName() string {
+	return "Allowlist"
+}
+
+// Validate has nothing to check.
+func (a Allowlist) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters fills in registered queries sent by hash only and enforces strict mode.
+func (a Allowlist) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if rawParams.Query == "" {
+		var extension struct {
+			Sha256 string `mapstructure:"sha256Hash"`
+		}
+		if err := mapstructure.Decode(rawParams.Extensions["persistedQuery"], &extension); err == nil {
+			if query, ok := a.Manifest[extension.Sha256]; ok {
+				rawParams.Query = query
+				return nil
+			}
+		}
+		// ? Unknown hashes are left to the automatic persisted queries extension
+		if !a.Strict {
+			return nil
+		}
+		return notAllowed()
+	}
+
+	if a.Strict {
+		if _, ok := a.Manifest[Hash(rawParams.Query)]; !ok {
+			return notAllowed()
+		}
+	}
+	return nil
+}
+
+// notAllowed builds the error returned for operations missing from the manifest.
+func notAllowed() *gqlerror.Error {
+	err := gqlerror.Errorf("operation is not in the allowlist")
+	errcode.Set(err, errOperationNotAllowedCode)
+	return err
+}
@@ -0,0 +1,41 @@
+package persisted
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"log/slog"
+)
+
+const cachePrefix = "apq:"
+
+// RedisCache stores automatic persisted queries in Redis so every instance shares them.
+type RedisCache struct {
+	RC  *redis.Client
+	TTL time.Duration
+}
+
+// Get looks up the query stored for a hash.
+func (c RedisCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	query, err := c.RC.Get(ctx, cachePrefix+key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("error fetching persisted query", "hash", key, "error", err)
+		}
+		return nil, false
+	}
+	return query, true
+}
+
+// Add stores the query for a hash.
+func (c RedisCache) Add(ctx context.Context, key string, value interface{}) {
+	query, ok := value.(string)
+	if !ok {
+		return
+	}
+	if err := c.RC.Set(ctx, cachePrefix+key, query, c.TTL).Err(); err != nil {
+		slog.Warn("error storing persisted query", "hash", key, "error", err)
+	}
+}
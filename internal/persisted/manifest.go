@@ -0,0 +1,46 @@
+package persisted
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+)
+
+// Manifest maps SHA-256 hashes of registered operations to their query text.
+type Manifest map[string]string
+
+// Hash returns the hex encoded SHA-256 hash of a query, as used by persisted queries.
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Add registers a query and returns its hash.
+func (m Manifest) Add(query string) string {
+	hash := Hash(query)
+	m[hash] = query
+	return hash
+}
+
+// LoadManifest reads a manifest from a JSON file.
+func LoadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := make(Manifest)
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest to a JSON file.
+func (m Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,63 @@
+package pubsub
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Broker fans out published messages to every subscriber of a topic.
+type Broker[T any] struct {
+	mu     sync.RWMutex
+	topics map[string]map[chan T]struct{}
+	buffer int
+}
+
+// NewBroker creates a broker whose subscriber channels hold up to buffer messages.
+func NewBroker[T any](buffer int) *Broker[T] {
+	return &Broker[T]{
+		topics: make(map[string]map[chan T]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscribe registers a subscriber for the topic. The returned channel is closed
+// once ctx is done.
+func (b *Broker[T]) Subscribe(ctx context.Context, topic string) <-chan T {
+	ch := make(chan T, b.buffer)
+
+	b.mu.Lock()
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[chan T]struct{})
+	}
+	b.topics[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.topics[topic], ch)
+		if len(b.topics[topic]) == 0 {
+			delete(b.topics, topic)
+		}
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Publish delivers the message to every subscriber of the topic. Subscribers
+// whose buffer is full miss the message instead of blocking the publisher.
+func (b *Broker[T]) Publish(topic string, msg T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.topics[topic] {
+		select {
+		case ch <- msg:
+		default:
+			slog.Warn("subscriber is too slow, dropping message", "topic", topic)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"log/slog"
+)
+
+const errRateLimitedCode = "RATE_LIMITED"
+
+type clientIPKey struct{}
+
+// ParseProxies parses the trusted proxies from the configuration, given as IPs or CIDR ranges.
+func ParseProxies(specs []string) ([]netip.Prefix, error) {
+	proxies := make([]netip.Prefix, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(spec); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", spec, ErrInvalidProxy)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// Middleware stores the client IP of the request for per-IP rules. X-Forwarded-For
+// is only honored for requests that come through one of the trusted proxies.
+func Middleware(trusted []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, clientIP(r, trusted))))
+	})
+}
+
+// ClientIP returns the client IP stored by Middleware, or an empty string.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// clientIP returns the address the request originates from. Behind trusted proxies it is
+// the last X-Forwarded-For entry that is not a trusted proxy itself, as the entries
+// before it were written by the client and cannot be relied on.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrusted(host, trusted) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrusted(hop, trusted) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
+
+// isTrusted reports whether the address belongs to a trusted proxy.
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// MutationLimit applies the configured rules to root mutation fields.
+type MutationLimit struct {
+	Limiter Limiter
+	Rules   []Rule
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = MutationLimit{}
+
+// ExtensionName returns the extension name.
+func (MutationLimit) ExtensionName() string {
+	return "MutationLimit"
+}
+
+// Validate has nothing to check.
+func (MutationLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField checks every rule of a mutation before it is resolved.
+func (m MutationLimit) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" {
+		return next(ctx)
+	}
+
+	for _, rule := range m.Rules {
+		if rule.Operation != fc.Field.Name {
+			continue
+		}
+		subject := m.subject(ctx, rule.Scope)
+		if subject == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s:%s", rule.Operation, rule.Scope, subject)
+		allowed, retryAfter, err := m.Limiter.Allow(ctx, key, rule.Limit, rule.Window)
+		if err != nil {
+			// ? A limiter outage should not take mutations down with it
+			slog.Warn("error checking rate limit", "key", key, "error", err)
+			continue
+		}
+		if !allowed {
+			slog.Warn("rate limit exceeded", "key", key, "retry_after", retryAfter)
+			return nil, rateLimited(rule, retryAfter)
+		}
+	}
+	return next(ctx)
+}
+
+// subject returns who a rule of the scope counts, or an empty string if unknown.
+// Per-user rules count anonymous requests by client IP instead.
+func (MutationLimit) subject(ctx context.Context, scope Scope) string {
+	switch scope {
+	case ScopeUser:
+		if user := auth.FromContext(ctx).User; user != "" {
+			return user
+		}
+		if ip := ClientIP(ctx); ip != "" {
+			return "ip:" + ip
+		}
+		return ""
+	case ScopeIP:
+		return ClientIP(ctx)
+	default:
+		return ""
+	}
+}
+
+// rateLimited builds the error returned once a rule is exhausted.
+func rateLimited(rule Rule, retryAfter time.Duration) *gqlerror.Error {
+	err := gqlerror.Errorf("too many %s requests, limit is %d per %s", rule.Operation, rule.Limit, rule.Window)
+	errcode.Set(err, errRateLimitedCode)
+	err.Extensions["retryAfter"] = int(math.Ceil(retryAfter.Seconds()))
+	return err
+}
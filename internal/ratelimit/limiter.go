@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter counts requests per key in a sliding window.
+type Limiter interface {
+	// Allow records a request for key and reports whether it fits in limit requests per window.
+	// When it does not, retryAfter tells how long until the oldest request leaves the window.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// MemoryLimiter keeps request timestamps in process memory. It is meant for a
+// single instance or tests, as limits are not shared between instances.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	requests  map[string][]time.Time
+	longest   time.Duration // ? Longest window checked so far, history within it is still needed
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval is how often keys without recent requests are dropped.
+const sweepInterval = time.Minute
+
+// NewMemoryLimiter creates an empty in-memory limiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		requests: make(map[string][]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Allow implements Limiter.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if window > l.longest {
+		l.longest = window
+	}
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	requests := inWindow(l.requests[key], now.Add(-window))
+	if len(requests) >= limit {
+		l.requests[key] = requests
+		return false, requests[0].Add(window).Sub(now), nil
+	}
+	l.requests[key] = append(requests, now)
+	return true, 0, nil
+}
+
+// sweep drops keys whose last request left the longest window, so no rule loses history it still counts.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, requests := range l.requests {
+		if len(requests) == 0 || now.Sub(requests[len(requests)-1]) > l.longest {
+			delete(l.requests, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// inWindow drops the timestamps at or before since.
+func inWindow(requests []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(requests) && !requests[i].After(since) {
+		i++
+	}
+	return requests[i:]
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const keyPrefix = "ratelimit:"
+
+// slidingWindow keeps one sorted set member per request scored by its time in milliseconds.
+// It returns 0 when the request is allowed, otherwise the milliseconds until a slot frees up.
+var slidingWindow = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return tonumber(oldest[2]) + window - now
+`)
+
+// RedisLimiter shares request counts between instances through Redis.
+type RedisLimiter struct {
+	RC *redis.Client
+}
+
+// NewRedisLimiter creates a limiter backed by the given Redis client.
+func NewRedisLimiter(rc *redis.Client) *RedisLimiter {
+	return &RedisLimiter{RC: rc}
+}
+
+// Allow implements Limiter.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+	wait, err := slidingWindow.Run(ctx, l.RC, []string{keyPrefix + key}, now, window.Milliseconds(), limit, member).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRule  = errors.New("rate limit rule must look like operation:scope=limit/window")
+	ErrInvalidProxy = errors.New("trusted proxy must be an IP address or a CIDR range")
+)
+
+// Scope tells what a rule counts requests by.
+type Scope string
+
+const (
+	ScopeUser Scope = "user"
+	ScopeIP   Scope = "ip"
+)
+
+// Rule limits how often a mutation can be called per user or per client IP.
+type Rule struct {
+	Operation string
+	Scope     Scope
+	Limit     int
+	Window    time.Duration
+}
+
+// ParseRules parses rules such as "createComment:user=10/1m" from the configuration.
+func ParseRules(specs []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		rule, err := parseRule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", spec, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRule parses a single operation:scope=limit/window rule.
+func parseRule(spec string) (Rule, error) {
+	operation, rest, ok := strings.Cut(spec, ":")
+	if !ok || operation == "" {
+		return Rule{}, ErrInvalidRule
+	}
+	scope, rest, ok := strings.Cut(rest, "=")
+	if !ok || (Scope(scope) != ScopeUser && Scope(scope) != ScopeIP) {
+		return Rule{}, ErrInvalidRule
+	}
+	count, window, ok := strings.Cut(rest, "/")
+	if !ok {
+		return Rule{}, ErrInvalidRule
+	}
+
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit <= 0 {
+		return Rule{}, ErrInvalidRule
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return Rule{}, ErrInvalidRule
+	}
+	return Rule{Operation: operation, Scope: Scope(scope), Limit: limit, Window: duration}, nil
+}
@@ -0,0 +1,203 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"log/slog"
+)
+
+// Transport serves operations, subscriptions in particular, over Server-Sent Events
+// following the graphql-sse protocol in distinct connections mode.
+//
+// Events whose payload carries a sequence number are sent with it as the event ID,
+// so a reconnecting client reports it back in the Last-Event-ID header and
+// subscriptions resume from there.
+type Transport struct {
+	KeepAliveInterval time.Duration // ? Comment lines sent while no events flow
+	RetryInterval     time.Duration // ? Reconnection delay advertised to clients
+}
+
+var _ graphql.Transport = Transport{}
+
+type lastEventIDKey struct{}
+
+// LastEventID returns the event ID a reconnecting client reported, if any.
+func LastEventID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(lastEventIDKey{}).(int64)
+	return id, ok
+}
+
+// Supports accepts GET and JSON POST requests that ask for an event stream.
+func (t Transport) Supports(r *http.Request) bool {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+	if r.Method == http.MethodGet {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return r.Method == http.MethodPost && mediaType == "application/json"
+}
+
+// Do executes the operation and streams every result as a "next" event,
+// finishing with a "complete" event.
+func (t Transport) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	ctx := r.Context()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		transport.SendErrorf(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	start := graphql.Now()
+	params, err := readParams(r)
+	if err != nil {
+		transport.SendErrorf(w, http.StatusBadRequest, "could not read request: %s", err)
+		return
+	}
+	params.Headers = r.Header
+	params.ReadTime = graphql.TraceTiming{Start: start, End: graphql.Now()}
+
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		if id, err := strconv.ParseInt(header, 10, 64); err == nil {
+			ctx = context.WithValue(ctx, lastEventIDKey{}, id)
+		}
+	}
+
+	rc, opErr := exec.CreateOperationContext(ctx, params)
+	ctx = graphql.WithOperationContext(ctx, rc)
+	if opErr != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		writeJSON(w, exec.DispatchError(ctx, opErr))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", t.RetryInterval.Milliseconds())
+	flusher.Flush()
+
+	responses, ctx := exec.DispatchOperation(ctx, rc)
+	results := make(chan *graphql.Response)
+	go func() {
+		defer close(results)
+		for {
+			response := responses(ctx)
+			if response == nil {
+				return
+			}
+			select {
+			case results <- response:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(t.KeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case response, ok := <-results:
+			if !ok {
+				fmt.Fprint(w, "event: complete\ndata:\n\n")
+				flusher.Flush()
+				return
+			}
+			writeEvent(w, response)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ":\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readParams decodes operation parameters from the query string of a GET
+// request or from the JSON body of a POST request.
+func readParams(r *http.Request) (*graphql.RawParams, error) {
+	params := &graphql.RawParams{}
+
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, params); err != nil {
+			return nil, err
+		}
+		return params, nil
+	}
+
+	query := r.URL.Query()
+	params.Query = query.Get("query")
+	params.OperationName = query.Get("operationName")
+	if variables := query.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+			return nil, fmt.Errorf("variables: %w", err)
+		}
+	}
+	if extensions := query.Get("extensions"); extensions != "" {
+		if err := json.Unmarshal([]byte(extensions), &params.Extensions); err != nil {
+			return nil, fmt.Errorf("extensions: %w", err)
+		}
+	}
+	return params, nil
+}
+
+// writeEvent writes a "next" event, tagged with the payload's sequence number when it has one.
+func writeEvent(w io.Writer, response *graphql.Response) {
+	b, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("error marshaling SSE response", "error", err)
+		return
+	}
+	if id, ok := sequenceOf(response.Data); ok {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: next\ndata: %s\n\n", b)
+}
+
+// sequenceOf extracts the sequence field of a single root field payload.
+func sequenceOf(data json.RawMessage) (int64, bool) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil || len(root) != 1 {
+		return 0, false
+	}
+	for _, value := range root {
+		var payload struct {
+			Sequence *int64 `json:"sequence"`
+		}
+		if err := json.Unmarshal(value, &payload); err != nil || payload.Sequence == nil {
+			return 0, false
+		}
+		return *payload.Sequence, true
+	}
+	return 0, false
+}
+
+// writeJSON writes a single JSON response.
+func writeJSON(w io.Writer, response *graphql.Response) {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("error writing response", "error", err)
+	}
+}
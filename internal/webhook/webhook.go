@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderID        = "X-Webhook-Id" // ? Event ID, the same for every retry so receivers can drop duplicates
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var ErrUnexpectedStatus = errors.New("webhook endpoint returned a non-2xx status")
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Sign returns the signature of a delivery body: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret, prefixed with "sha256=".
+// Receivers recompute it to check the body came from us and was not replayed.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender posts signed deliveries to webhook endpoints.
+type Sender struct {
+	Client *http.Client
+}
+
+// NewSender creates a sender whose requests give up after timeout.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{Client: &http.Client{Timeout: timeout}}
+}
+
+// Send posts the body to url and returns the response status. Any status other
+// than 2xx is returned together with ErrUnexpectedStatus.
+func (s *Sender) Send(ctx context.Context, url, secret, id, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, id)
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// ? Drain a bounded part of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Policy decides when failed deliveries are retried.
+type Policy struct {
+	MaxAttempts int           // Attempts before a delivery is dead-lettered
+	Base        time.Duration // Delay after the first failed attempt, doubled after each further one
+	Max         time.Duration // Upper bound of the delay
+}
+
+// Next returns the delay before retrying a delivery that failed attempts times,
+// or false once it is out of attempts.
+func (p Policy) Next(attempts int) (time.Duration, bool) {
+	if attempts >= p.MaxAttempts {
+		return 0, false
+	}
+	return Backoff(attempts, p.Base, p.Max), true
+}
+
+// Backoff returns the delay before retrying work that failed attempts times:
+// base, doubled after each further failure, up to max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
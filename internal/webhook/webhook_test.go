@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{
+			name:      "body",
+			secret:    "secret",
+			timestamp: 1700000000,
+			body:      `{"id":"1"}`,
+			want:      "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54",
+		},
+		{
+			name:      "other secret",
+			secret:    "other",
+			timestamp: 1700000000,
+			body:      `{"id":"1"}`,
+			want:      "sha256=0c9dcd041b074d1b31727e0c1f821d11366e9db9f94c18bf202eb66cd0bd4d40",
+		},
+		{
+			name:      "empty body",
+			secret:    "secret",
+			timestamp: 1700000000,
+			body:      "",
+			want:      "sha256=4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignCoversTimestamp(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	if Sign("secret", 1700000000, body) == Sign("secret", 1700000001, body) {
+		t.Error("Sign() returned the same signature for different timestamps")
+	}
+}
+
+func TestPolicyNext(t *testing.T) {
+	policy := Policy{MaxAttempts: 5, Base: time.Second, Max: 5 * time.Second}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+		ok       bool
+	}{
+		{attempts: 1, want: time.Second, ok: true},
+		{attempts: 2, want: 2 * time.Second, ok: true},
+		{attempts: 3, want: 4 * time.Second, ok: true},
+		{attempts: 4, want: 5 * time.Second, ok: true},
+		{attempts: 5, ok: false},
+		{attempts: 6, ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := policy.Next(tt.attempts)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Next(%d) = %v, %v, want %v, %v", tt.attempts, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		base     time.Duration
+		max      time.Duration
+		want     time.Duration
+	}{
+		{attempts: 1, base: time.Second, max: time.Minute, want: time.Second},
+		{attempts: 2, base: time.Second, max: time.Minute, want: 2 * time.Second},
+		{attempts: 4, base: time.Second, max: time.Minute, want: 8 * time.Second},
+		{attempts: 7, base: time.Second, max: time.Minute, want: time.Minute},
+		{attempts: 100, base: time.Second, max: time.Minute, want: time.Minute},
+		{attempts: 1, base: 2 * time.Minute, max: time.Minute, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, tt.base, tt.max); got != tt.want {
+			t.Errorf("Backoff(%d, %s, %s) = %s, want %s", tt.attempts, tt.base, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// limiter counts the active subscriptions of one websocket connection.
+type limiter struct {
+	mu     sync.Mutex
+	active int
+	max    int
+}
+
+type limiterKey struct{}
+
+// withLimiter attaches a subscription limiter to the connection context.
+func withLimiter(ctx context.Context, max int) context.Context {
+	return context.WithValue(ctx, limiterKey{}, &limiter{max: max})
+}
+
+// acquire reserves a subscription slot, reporting false when the connection is at its limit.
+func (l *limiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max > 0 && l.active >= l.max {
+		return false
+	}
+	l.active++
+	return true
+}
+
+// release frees a subscription slot.
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+}
+
+// SubscriptionLimit rejects subscriptions beyond the per-connection limit set up by the websocket transport.
+type SubscriptionLimit struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = SubscriptionLimit{}
+
+// ExtensionName returns the extension name.
+func (SubscriptionLimit) ExtensionName() string {
+	return "SubscriptionLimit"
+}
+
+// Validate has nothing to check.
+func (SubscriptionLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation reserves a slot for every subscription and frees it once the subscription ends.
+func (SubscriptionLimit) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	l, ok := ctx.Value(limiterKey{}).(*limiter)
+	op := graphql.GetOperationContext(ctx).Operation
+	if !ok || op == nil || op.Operation != ast.Subscription {
+		return next(ctx)
+	}
+
+	if !l.acquire() {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "too many active subscriptions on this connection"))
+	}
+
+	responses := next(ctx)
+	var once sync.Once
+	return func(ctx context.Context) *graphql.Response {
+		response := responses(ctx)
+		if response == nil {
+			once.Do(l.release)
+		}
+		return response
+	}
+}
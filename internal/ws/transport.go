@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+	"github.com/likimiad/ozon_fintech/internal/auth"
+	"github.com/likimiad/ozon_fintech/internal/config"
+	"log/slog"
+)
+
+// Subprotocols supported by the transport, the modern one preferred.
+var subprotocols = []string{"graphql-transport-ws", "graphql-ws"}
+
+// NewTransport creates the websocket transport speaking both graphql-transport-ws
+// and the legacy graphql-ws subprotocol. Connections are authenticated from the
+// connection_init payload and limited in the number of active subscriptions.
+func NewTransport(cfg config.WebsocketConfig, authenticator *auth.Authenticator) transport.Websocket {
+	return transport.Websocket{
+		Upgrader: websocket.Upgrader{
+			Subprotocols: subprotocols,
+			CheckOrigin: func(r *http.Request) bool {
+				return originAllowed(cfg.AllowedOrigins, r.Header.Get("Origin"), r.Host)
+			},
+		},
+		InitFunc:              initFunc(cfg, authenticator),
+		KeepAlivePingInterval: cfg.KeepAlive,
+	}
+}
+
+// initFunc validates the credentials of the connection_init payload. Without
+// credentials in the payload, the viewer of the upgrade request is kept.
+func initFunc(cfg config.WebsocketConfig, authenticator *auth.Authenticator) transport.WebsocketInitFunc {
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		user := payload.GetString("user")
+		token := auth.BearerToken(payload.Authorization())
+
+		if user != "" || token != "" {
+			viewer, err := authenticator.Authenticate(user, token)
+			if err != nil {
+				slog.Warn("websocket connection rejected", "error", err)
+				return ctx, nil, err
+			}
+			ctx = auth.WithViewer(ctx, viewer)
+		}
+
+		return withLimiter(ctx, cfg.MaxSubscriptions), nil, nil
+	}
+}
+
+// originAllowed reports whether a browser origin may open a websocket to host.
+// Requests without an Origin header come from non-browser clients and are allowed.
+// Other origins than the server's own must be listed, "*" allows every origin.
+func originAllowed(allowed []string, origin, host string) bool {
+	if origin == "" || slices.Contains(allowed, "*") || slices.Contains(allowed, origin) {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, host)
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"time"
+	_ "time/tzdata" // ? timeZone arguments work without zoneinfo in the runtime image
 
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/gorilla/websocket"
 	"github.com/likimiad/ozon_fintech/graph"
 	"github.com/likimiad/ozon_fintech/graph/generated"
+	"github.com/likimiad/ozon_fintech/internal/auth"
 	"github.com/likimiad/ozon_fintech/internal/config"
 	"github.com/likimiad/ozon_fintech/internal/database"
 	"github.com/likimiad/ozon_fintech/internal/logger"
+	"github.com/likimiad/ozon_fintech/internal/persisted"
+	"github.com/likimiad/ozon_fintech/internal/ratelimit"
+	"github.com/likimiad/ozon_fintech/internal/sse"
+	"github.com/likimiad/ozon_fintech/internal/ws"
 	"log/slog"
 )
 
@@ -26,31 +33,87 @@ func main() {
 		logger.FatalError("error while making connection with database", err)
 	}
 
+	// ? Permanently remove posts whose restore window has passed
+	postService.StartPurgeJob(context.Background())
+
+	// ? Send queued webhook deliveries, retrying failures with backoff
+	postService.StartWebhookWorker(context.Background(), cfg.WebhookConfig)
+
+	// ? Relay outbox events their writer could not deliver to caches, subscribers and webhooks
+	postService.StartOutboxRelay(context.Background(), cfg.OutboxConfig)
+
 	// ? GraphQL resolver
 	resolver := graph.NewResolver(postService)
 
 	// ? GraphQL server
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{
+	// ! Transports are matched in order, so they are registered explicitly instead of
+	// ! using handler.NewDefaultServer, whose defaults would shadow the ones below
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
 		Resolvers: resolver,
 	}))
 
-	// ? WebSocket transport for subscriptions
-	srv.AddTransport(transport.Websocket{
-		Upgrader: websocket.Upgrader{
-			// ! Allow all origins for WebSocket connections
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
-		},
-		KeepAlivePingInterval: 10 * time.Second, // ? Keep WebSocket connection alive with pings every 10 seconds
+	// ? Viewer identity and role from X-User and Authorization headers
+	authenticator := auth.NewAuthenticator(cfg.AuthConfig)
+
+	// ? WebSocket transport for subscriptions (graphql-transport-ws and legacy graphql-ws)
+	srv.AddTransport(ws.NewTransport(cfg.WebsocketConfig, authenticator))
+	srv.Use(ws.SubscriptionLimit{})
+
+	// ? Server-Sent Events transport for clients behind proxies that break websockets
+	srv.AddTransport(sse.Transport{
+		KeepAliveInterval: cfg.ServerConfig.SSEKeepAlive,
+		RetryInterval:     cfg.ServerConfig.SSERetry,
 	})
 
 	// ? Add POST transport for standard GraphQL queries and mutations
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	// ? Comment trees are loaded once per query instead of once per replies field,
+	// ? and the authors of a list are fetched together
+	srv.AroundOperations(resolver.Loaders)
+
+	srv.SetQueryCache(lru.New(1000))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	srv.Use(extension.Introspection{})
+
+	// ? Operations registered in the manifest, required for every request in strict mode
+	manifest := make(persisted.Manifest)
+	if path := cfg.PersistedQueriesConfig.ManifestPath; path != "" {
+		if manifest, err = persisted.LoadManifest(path); err != nil {
+			logger.FatalError("error loading persisted queries manifest", err)
+		}
+	}
+	srv.Use(persisted.Allowlist{
+		Manifest: manifest,
+		Strict:   cfg.PersistedQueriesConfig.Strict,
+	})
+
+	// ? Automatic persisted queries shared between instances through Redis
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: persisted.RedisCache{RC: postService.RC, TTL: cfg.PersistedQueriesConfig.APQTTL},
+	})
+
+	// ? Per-user and per-IP limits on mutations
+	rules, err := ratelimit.ParseRules(cfg.RateLimitConfig.Rules)
+	if err != nil {
+		logger.FatalError("error parsing rate limit rules", err)
+	}
+	proxies, err := ratelimit.ParseProxies(cfg.ServerConfig.TrustedProxies)
+	if err != nil {
+		logger.FatalError("error parsing trusted proxies", err)
+	}
+	var limiter ratelimit.Limiter = ratelimit.NewRedisLimiter(postService.RC)
+	if cfg.RateLimitConfig.Backend == "memory" {
+		limiter = ratelimit.NewMemoryLimiter()
+	}
+	srv.Use(ratelimit.MutationLimit{Limiter: limiter, Rules: rules})
 
 	http.Handle("/docs/", http.StripPrefix("/docs/", http.FileServer(http.Dir("public"))))
 
-	http.Handle("/query", srv)
+	http.Handle("/query", ratelimit.Middleware(proxies, authenticator.Middleware(srv)))
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 
 	slog.Info(fmt.Sprintf("connect to http://localhost:%s/ for GraphQL playground", cfg.ServerConfig.Port))